@@ -0,0 +1,306 @@
+package shigoto
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
+)
+
+// Calendar is a set of the dates a job doesn't run on, like the weekends
+// and the public holidays. The dates are matched on the wall clock of the
+// time zone of the job. A Calendar is safe for concurrent use, so it can
+// be changed while the scheduler runs.
+type Calendar struct {
+	name     string
+	lock     sync.RWMutex
+	weekdays [7]bool
+	dates    map[date]string // The excluded dates and their names
+	yearly   map[date]string // The dates excluded every year, the year is 0
+}
+
+// date is a day of a Calendar, without a time zone
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// calendarLimit is how many years the next run is searched
+// for a date which is not excluded by the calendars
+const calendarLimit = 5
+
+// ErrExcluded is returned when the calendars and the time windows
+// of a job exclude all of its runs
+var ErrExcluded = errors.New("Calendars and time windows exclude every run of the job")
+
+// weekends is the calendar of OnlyOnBusinessDays
+var weekends = NewCalendar("weekends").ExcludeWeekdays(time.Saturday, time.Sunday)
+
+// NewCalendar to create an empty calendar, the name is used in the logs
+func NewCalendar(name string) *Calendar {
+	return &Calendar{
+		name:   name,
+		dates:  make(map[date]string),
+		yearly: make(map[date]string),
+	}
+}
+
+// Name returns the name of the calendar
+func (cal *Calendar) Name() string {
+	return cal.name
+}
+
+// ExcludeWeekdays to exclude every week on the weekdays
+func (cal *Calendar) ExcludeWeekdays(days ...time.Weekday) *Calendar {
+	cal.lock.Lock()
+	defer cal.lock.Unlock()
+
+	for _, day := range days {
+		cal.weekdays[day%7] = true
+	}
+
+	return cal
+}
+
+// ExcludeDates to exclude the dates of the times, only
+// the year, month and day of the times are used
+func (cal *Calendar) ExcludeDates(dates ...time.Time) *Calendar {
+	cal.lock.Lock()
+	defer cal.lock.Unlock()
+
+	for _, t := range dates {
+		year, month, day := t.Date()
+		cal.dates[date{year, month, day}] = ""
+	}
+
+	return cal
+}
+
+// ExcludeEveryYear to exclude a date every year, like Christmas on December 25
+func (cal *Calendar) ExcludeEveryYear(month time.Month, day int) *Calendar {
+	cal.lock.Lock()
+	defer cal.lock.Unlock()
+
+	cal.yearly[date{0, month, day}] = ""
+
+	return cal
+}
+
+// Excludes reports if the date of t is excluded by the calendar
+func (cal *Calendar) Excludes(t time.Time) bool {
+	cal.lock.RLock()
+	defer cal.lock.RUnlock()
+
+	year, month, day := t.Date()
+	if cal.weekdays[t.Weekday()] {
+		return true
+	}
+	if _, ok := cal.dates[date{year, month, day}]; ok {
+		return true
+	}
+	_, ok := cal.yearly[date{0, month, day}]
+
+	return ok
+}
+
+// ImportICSFile to import the events of an iCalendar file, see ImportICS
+func (cal *Calendar) ImportICSFile(path string) error {
+	f, e := os.Open(path)
+	if e != nil {
+		return e
+	}
+	defer f.Close()
+
+	return cal.ImportICS(f)
+}
+
+// ImportICS to exclude the dates of the events of an iCalendar (.ics),
+// like the public holidays exported by a calendar app. An event excludes
+// every date from its DTSTART until its DTEND, a yearly event
+// (RRULE:FREQ=YEARLY) excludes its dates every year. The other
+// recurrence rules are not supported.
+func (cal *Calendar) ImportICS(r io.Reader) error {
+	lines, e := unfoldICS(r)
+	if e != nil {
+		return e
+	}
+
+	type event struct {
+		summary, rrule string
+		start, end     time.Time
+		endDate        bool // DTEND is a date, it is not included
+	}
+	var ev *event
+	var events []event
+	for i, line := range lines {
+		name, value := icsProperty(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			ev = &event{}
+		case name == "END" && value == "VEVENT" && ev != nil:
+			if ev.start.IsZero() {
+				return fmt.Errorf("Calendar event %q at line %d has no DTSTART", ev.summary, i+1)
+			}
+			events = append(events, *ev)
+			ev = nil
+		case ev == nil:
+		case name == "SUMMARY":
+			ev.summary = value
+		case name == "RRULE":
+			ev.rrule = value
+		case name == "DTSTART", name == "DTEND":
+			t, e := icsDate(value)
+			if e != nil {
+				return fmt.Errorf("Calendar %s %q at line %d is invalid, use YYYYMMDD", name, value, i+1)
+			}
+			if name == "DTSTART" {
+				ev.start = t
+			} else {
+				ev.end, ev.endDate = t, len(value) == 8 || strings.HasSuffix(value, "T000000") || strings.HasSuffix(value, "T000000Z")
+			}
+		}
+	}
+
+	for _, ev := range events {
+		if ev.rrule != "" && !strings.Contains(strings.ToUpper(ev.rrule), "FREQ=YEARLY") {
+			return fmt.Errorf("Calendar event %q has the unsupported recurrence %s", ev.summary, ev.rrule)
+		}
+	}
+
+	cal.lock.Lock()
+	defer cal.lock.Unlock()
+
+	for _, ev := range events {
+		last := ev.start
+		if !ev.end.IsZero() {
+			last = ev.end
+			if ev.endDate {
+				last = last.AddDate(0, 0, -1)
+			}
+		}
+
+		for d := ev.start; !d.After(last); d = d.AddDate(0, 0, 1) {
+			year, month, day := d.Date()
+			if ev.rrule != "" {
+				cal.yearly[date{0, month, day}] = ev.summary
+			} else {
+				cal.dates[date{year, month, day}] = ev.summary
+			}
+		}
+	}
+
+	return nil
+}
+
+// unfoldICS reads the lines of an iCalendar, a line starting with
+// a space or a tab continues the previous line
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// icsProperty splits a line like `DTSTART;VALUE=DATE:20210101`
+// into the name and the value of the property
+func icsProperty(line string) (name, value string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", ""
+	}
+	name = line[:i]
+	if j := strings.Index(name, ";"); j >= 0 {
+		name = name[:j]
+	}
+
+	return strings.ToUpper(name), strings.TrimSpace(line[i+1:])
+}
+
+// icsDate parses the date of a DATE or DATE-TIME value,
+// the time of a DATE-TIME is ignored
+func icsDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("date too short")
+	}
+
+	return time.Parse("20060102", value[:8])
+}
+
+// ExcludeCalendar to skip the runs of the job on the dates of the calendar,
+// the job runs on its next date which is not excluded
+func (j *Jobs) ExcludeCalendar(cal *Calendar) *Jobs {
+	if cal == nil {
+		j.addErr(errors.New("The calendar to exclude is nil"))
+		return j
+	}
+
+	j.calendars = append(j.calendars, cal)
+	return j
+}
+
+// OnlyOnBusinessDays to run the job from Monday to Friday,
+// the dates of the calendar like the public holidays are skipped.
+// The calendar can be nil to skip only the weekends.
+func (j *Jobs) OnlyOnBusinessDays(cal *Calendar) *Jobs {
+	j.calendars = append(j.calendars, weekends)
+	if cal != nil {
+		j.calendars = append(j.calendars, cal)
+	}
+
+	return j
+}
+
+// excludedBy returns the first calendar which excludes the date of t
+func excludedBy(calendars []*Calendar, t time.Time) *Calendar {
+	for _, cal := range calendars {
+		if cal.Excludes(t) {
+			return cal
+		}
+	}
+
+	return nil
+}
+
+// skipExcluded moves next to the first run of the expression on a date
+// which is not excluded by the calendars and in the time windows of the job.
+// The dates and the times are matched on the wall clock of the location of next.
+func skipExcluded(expr *cronparser.Expression, anchor, next time.Time, o jobOptions) (time.Time, error) {
+	limit := next.AddDate(calendarLimit, 0, 0)
+	for !next.IsZero() {
+		// The runs until the date or the time is allowed again are skipped at once
+		var resume time.Time
+		if excludedBy(o.calendars, next) != nil {
+			year, month, day := next.Date()
+			resume = time.Date(year, month, day+1, 0, 0, 0, 0, next.Location())
+		} else if w, ok := deniedBy(o.windows, next); ok {
+			resume = w.resume(next)
+		} else {
+			break
+		}
+		if next.After(limit) || resume.IsZero() {
+			return time.Time{}, ErrExcluded
+		}
+
+		next = expr.NextSince(anchor, resume.Add(-time.Second))
+		if next.IsZero() {
+			return next, cronparser.ErrNeverMatches
+		}
+	}
+
+	return next, nil
+}
@@ -0,0 +1,446 @@
+// Command shigoto operates the persistent storage of a scheduler,
+// to inspect and repair the jobs without the mongo shell.
+//
+//	shigoto [flags] <command> [arguments]
+//
+// The changes are loaded by a running scheduler at its SyncInterval.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
+	"github.com/KodepandaID/shigoto/pkg/mongodb-connector"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const usage = `Usage: shigoto [flags] <command> [arguments]
+
+Commands:
+  list                      List the jobs and their stats
+  show <job>                Show a job and its params
+  history <job>             Show the latest runs of a job
+  pause <job>               Stop the scheduled runs of a job
+  resume <job>              Start the scheduled runs of a job again
+  trigger <job> [params]    Run a job now, with its params or the given params
+  delete <job>              Delete a job with its params and runs
+  next <cron expr>          Preview the next run times of a cron format
+  export                    Write the jobs and their params as JSON
+  import [file]             Create or update the jobs from the JSON of export,
+                            a running scheduler loads them when it restarts
+
+Flags:
+`
+
+type cli struct {
+	uri      string
+	dbName   string
+	timezone string
+	loc      *time.Location // The location of -tz, every time is printed in it
+	n        int
+	yes      bool
+	out      io.Writer
+	in       io.Reader
+	client   *mongodb.Connector
+}
+
+func main() {
+	c := &cli{out: os.Stdout, in: os.Stdin}
+
+	flag.StringVar(&c.uri, "uri", os.Getenv("MONGO_URI"), "The MongoDB uri")
+	flag.StringVar(&c.dbName, "db", envOr("SHIGOTO_DB", "jobs-scheduler"), "Database name from MongoDB")
+	flag.StringVar(&c.timezone, "tz", "Asia/Jakarta", "The timezone of the scheduler and of the printed times")
+	flag.IntVar(&c.n, "n", 10, "The number of runs for history and next")
+	flag.BoolVar(&c.yes, "y", false, "Delete without confirmation")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if e := c.run(flag.Arg(0), flag.Args()[1:]); e != nil {
+		fmt.Fprintln(os.Stderr, "shigoto:", e)
+		os.Exit(1)
+	}
+}
+
+func envOr(key, value string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return value
+}
+
+func (c *cli) run(cmd string, args []string) error {
+	loc, e := time.LoadLocation(c.timezone)
+	if e != nil {
+		return e
+	}
+	c.loc = loc
+
+	if cmd == "next" {
+		return c.next(args)
+	}
+
+	commands := map[string]func([]string) error{
+		"list":    c.list,
+		"show":    c.show,
+		"history": c.history,
+		"pause":   c.pause,
+		"resume":  c.resume,
+		"trigger": c.trigger,
+		"delete":  c.delete,
+		"export":  c.export,
+		"import":  c.importJobs,
+	}
+	f, ok := commands[cmd]
+	if !ok {
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+
+	if e := c.connect(); e != nil {
+		return e
+	}
+
+	return f(args)
+}
+
+func (c *cli) connect() error {
+	if c.uri == "" {
+		return errors.New("the MongoDB uri is empty, set -uri or MONGO_URI")
+	}
+
+	client, e := mongodb.New(&mongodb.Connector{
+		DB:     c.uri,
+		DBName: c.dbName,
+	})
+	if e != nil {
+		return e
+	}
+	if e := client.Ping(); e != nil {
+		return errors.New("MongoDB not connected")
+	}
+	c.client = client
+
+	return nil
+}
+
+// job returns the job of the only argument of a command
+func (c *cli) job(args []string) (mongodb.JobCollection, error) {
+	if len(args) != 1 {
+		return mongodb.JobCollection{}, errors.New("the job name is required")
+	}
+
+	job, e := c.client.GetOneJobCollection(args[0])
+	if e == mongo.ErrNoDocuments {
+		return job, fmt.Errorf("job %q is not registered", args[0])
+	}
+	if e != nil {
+		return job, e
+	}
+
+	return job, nil
+}
+
+func (c *cli) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+}
+
+func (c *cli) formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.In(c.loc).Format("2006-01-02 15:04:05")
+}
+
+func (c *cli) list(args []string) error {
+	jobs, e := c.client.GetJobCollection()
+	if e != nil {
+		return e
+	}
+
+	w := c.table()
+	fmt.Fprintln(w, "JOB\tCRON\tNEXT RUN\tLAST RUN\tTASKS\tRUNS\tSUCCESS\tPAUSED")
+	for _, job := range jobs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%.2f%%\t%t\n",
+			job.JobName, strings.Join(job.CronFormat, " "), c.formatTime(job.NextDate), c.formatTime(job.LastDate),
+			job.TotalTask, job.TotalRun, job.SuccessRate, job.Paused)
+	}
+
+	return w.Flush()
+}
+
+func (c *cli) show(args []string) error {
+	job, e := c.job(args)
+	if e != nil {
+		return e
+	}
+	tasks, e := c.client.GetTasks(job.ID)
+	if e != nil {
+		return e
+	}
+
+	w := c.table()
+	fmt.Fprintf(w, "Job:\t%s\n", job.JobName)
+	fmt.Fprintf(w, "Function:\t%s\n", job.FuncName)
+	fmt.Fprintf(w, "Cron:\t%s\n", strings.Join(job.CronFormat, " "))
+	if description, e := cronparser.Describe(cronparser.WithZone(job.CronFormat, job.Timezone)); e == nil {
+		fmt.Fprintf(w, "Schedule:\t%s\n", description)
+	}
+	if job.Timezone != "" {
+		fmt.Fprintf(w, "Time zone:\t%s\n", job.Timezone)
+	}
+	fmt.Fprintf(w, "Paused:\t%t\n", job.Paused)
+	fmt.Fprintf(w, "Next run:\t%s\n", c.formatTime(job.NextDate))
+	fmt.Fprintf(w, "Last run:\t%s\n", c.formatTime(job.LastDate))
+	fmt.Fprintf(w, "Runs:\t%d (%d manual, %d errors)\n", job.TotalRun, job.TotalManual, job.TotalError)
+	fmt.Fprintf(w, "Success rate:\t%.2f%%\n", job.SuccessRate)
+	fmt.Fprintf(w, "Error rate:\t%.2f%%\n", job.ErrorRate)
+	for i, task := range tasks {
+		params, _ := json.Marshal(task.Params)
+		fmt.Fprintf(w, "Params %d:\t%s\n", i+1, params)
+	}
+
+	return w.Flush()
+}
+
+func (c *cli) history(args []string) error {
+	job, e := c.job(args)
+	if e != nil {
+		return e
+	}
+	runs, e := c.client.GetRuns(job.JobName, int64(c.n))
+	if e != nil {
+		return e
+	}
+
+	w := c.table()
+	fmt.Fprintln(w, "STARTED\tSCHEDULED\tDURATION\tMANUAL\tPARAMS\tERROR")
+	for _, run := range runs {
+		params, _ := json.Marshal(run.Params)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n",
+			c.formatTime(run.StartedAt), c.formatTime(run.ScheduledAt), run.Duration, run.Manual, params, run.Error)
+	}
+
+	return w.Flush()
+}
+
+func (c *cli) pause(args []string) error {
+	return c.setPaused(args, true)
+}
+
+func (c *cli) resume(args []string) error {
+	return c.setPaused(args, false)
+}
+
+func (c *cli) setPaused(args []string, paused bool) error {
+	job, e := c.job(args)
+	if e != nil {
+		return e
+	}
+	if e := c.client.SetPaused(job.JobName, paused); e != nil {
+		return e
+	}
+
+	state := "resumed"
+	if paused {
+		state = "paused"
+	}
+	fmt.Fprintf(c.out, "Job %s is %s\n", job.JobName, state)
+
+	return nil
+}
+
+// trigger requests a run from the scheduler, the params are
+// decoded as JSON values or used as strings.
+func (c *cli) trigger(args []string) error {
+	if len(args) == 0 {
+		return errors.New("the job name is required")
+	}
+	job, e := c.job(args[:1])
+	if e != nil {
+		return e
+	}
+
+	var params []interface{}
+	for _, arg := range args[1:] {
+		var v interface{}
+		if json.Unmarshal([]byte(arg), &v) != nil {
+			v = arg
+		}
+		params = append(params, v)
+	}
+
+	if e := c.client.InsertTrigger(job.JobName, params...); e != nil {
+		return e
+	}
+	fmt.Fprintf(c.out, "Job %s is triggered, it runs at the next sync of the scheduler\n", job.JobName)
+
+	return nil
+}
+
+func (c *cli) delete(args []string) error {
+	job, e := c.job(args)
+	if e != nil {
+		return e
+	}
+
+	if !c.yes {
+		fmt.Fprintf(c.out, "Delete job %s with its params and runs? [y/N] ", job.JobName)
+		answer, _ := bufio.NewReader(c.in).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return errors.New("canceled")
+		}
+	}
+
+	if e := c.client.DeleteJobCollection(job.JobName); e != nil {
+		return e
+	}
+	fmt.Fprintf(c.out, "Job %s is deleted\n", job.JobName)
+
+	return nil
+}
+
+// next prints the next run times of the cron format,
+// given as a single argument or as its fields.
+func (c *cli) next(args []string) error {
+	expr := strings.Fields(strings.Join(args, " "))
+	if len(expr) == 0 {
+		return errors.New("the cron format is required")
+	}
+
+	t := time.Now().In(c.loc).Truncate(time.Second)
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: c.timezone,
+	})
+	schedule, e := parser.SetCurrentTime(t).Parse(expr)
+	if e != nil {
+		return e
+	}
+	if schedule.Next.IsZero() {
+		fmt.Fprintln(c.out, "Runs once every time the scheduler starts")
+		return nil
+	}
+
+	for _, next := range schedule.NextN(t, c.n) {
+		fmt.Fprintln(c.out, next.In(c.loc).Format("Mon 2006-01-02 15:04:05 MST"))
+	}
+
+	return nil
+}
+
+// exportJob is a job in the JSON of export and import
+type exportJob struct {
+	JobName    string          `json:"job_name"`
+	FuncName   string          `json:"func_name"`
+	CronFormat []string        `json:"cron_format"`
+	Timezone   string          `json:"timezone,omitempty"`
+	Paused     bool            `json:"paused"`
+	Params     [][]interface{} `json:"params"`
+}
+
+func (c *cli) export(args []string) error {
+	jobs, e := c.client.GetJobCollection()
+	if e != nil {
+		return e
+	}
+
+	out := make([]exportJob, 0, len(jobs))
+	for _, job := range jobs {
+		tasks, e := c.client.GetTasks(job.ID)
+		if e != nil {
+			return e
+		}
+
+		j := exportJob{
+			JobName:    job.JobName,
+			FuncName:   job.FuncName,
+			CronFormat: job.CronFormat,
+			Timezone:   job.Timezone,
+			Paused:     job.Paused,
+			Params:     make([][]interface{}, 0, len(tasks)),
+		}
+		for _, task := range tasks {
+			j.Params = append(j.Params, task.Params)
+		}
+		out = append(out, j)
+	}
+
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
+
+// importJobs creates the jobs of the JSON, a registered job gets the
+// function, cron format and paused state of the JSON and its missing
+// params. A running scheduler loads the new jobs when it starts.
+func (c *cli) importJobs(args []string) error {
+	in := c.in
+	if len(args) > 0 && args[0] != "-" {
+		f, e := os.Open(args[0])
+		if e != nil {
+			return e
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var jobs []exportJob
+	if e := json.NewDecoder(in).Decode(&jobs); e != nil {
+		return fmt.Errorf("the JSON is invalid: %v", e)
+	}
+
+	for _, job := range jobs {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: c.timezone,
+		})
+		schedule, e := parser.SetCurrentTime(time.Now().In(c.loc)).Parse(cronparser.WithZone(job.CronFormat, job.Timezone))
+		if e != nil {
+			return fmt.Errorf("job %q: %v", job.JobName, e)
+		}
+
+		payload := &mongodb.JobCollection{
+			JobName:    job.JobName,
+			FuncName:   job.FuncName,
+			CronFormat: job.CronFormat,
+			Timezone:   job.Timezone,
+			NextDate:   schedule.Next,
+		}
+		id, e := c.client.InsertJobCollection(payload)
+		if e == mongodb.ErrJobRegistered {
+			e = c.client.UpdateJobDefinition(id, payload)
+		}
+		if e != nil {
+			return fmt.Errorf("job %q: %v", job.JobName, e)
+		}
+
+		if e := c.client.SetPaused(job.JobName, job.Paused); e != nil {
+			return fmt.Errorf("job %q: %v", job.JobName, e)
+		}
+		for _, params := range job.Params {
+			if e := c.client.InsertTask(id, params...); e != nil {
+				return fmt.Errorf("job %q: %v", job.JobName, e)
+			}
+		}
+		fmt.Fprintf(c.out, "Job %s is imported\n", job.JobName)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package shigoto
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// JobEvent describes a run of a job, it is passed to the hooks
+type JobEvent struct {
+	RunID     string
+	JobName   string
+	FuncName  string
+	Params    []interface{}
+	Scheduled time.Time     // The time the run was scheduled at
+	Started   time.Time     // The actual time the run was started
+	Duration  time.Duration // Zero at OnStart
+	Attempt   int
+	Manual    bool // The run was started by Trigger
+	Error     error
+}
+
+type hooks struct {
+	start    []func(JobEvent)
+	success  []func(JobEvent)
+	failure  []func(JobEvent)
+	complete []func(JobEvent)
+}
+
+func (h *hooks) merge(o hooks) {
+	h.start = append(h.start, o.start...)
+	h.success = append(h.success, o.success...)
+	h.failure = append(h.failure, o.failure...)
+	h.complete = append(h.complete, o.complete...)
+}
+
+// jobOptions keeps the runtime options of a job which
+// can't be stored in the persistent storage.
+type jobOptions struct {
+	hooks      hooks
+	middleware []Middleware
+	calendars  []*Calendar
+	windows    []window
+}
+
+// OnStart to set a hook called before every job runs
+func (c *Config) OnStart(f func(JobEvent)) *Config {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	c.hooks.start = append(c.hooks.start, f)
+	return c
+}
+
+// OnSuccess to set a hook called after every job runs without error
+func (c *Config) OnSuccess(f func(JobEvent)) *Config {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	c.hooks.success = append(c.hooks.success, f)
+	return c
+}
+
+// OnFailure to set a hook called after every job returns an error or panics
+func (c *Config) OnFailure(f func(JobEvent)) *Config {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	c.hooks.failure = append(c.hooks.failure, f)
+	return c
+}
+
+// OnComplete to set a hook called after every job runs, success or not
+func (c *Config) OnComplete(f func(JobEvent)) *Config {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	c.hooks.complete = append(c.hooks.complete, f)
+	return c
+}
+
+// OnStart to set a hook called before the job runs
+func (j *Jobs) OnStart(f func(JobEvent)) *Jobs {
+	j.hooks.start = append(j.hooks.start, f)
+	return j
+}
+
+// OnSuccess to set a hook called after the job runs without error
+func (j *Jobs) OnSuccess(f func(JobEvent)) *Jobs {
+	j.hooks.success = append(j.hooks.success, f)
+	return j
+}
+
+// OnFailure to set a hook called after the job returns an error or panics
+func (j *Jobs) OnFailure(f func(JobEvent)) *Jobs {
+	j.hooks.failure = append(j.hooks.failure, f)
+	return j
+}
+
+// OnComplete to set a hook called after the job runs, success or not
+func (j *Jobs) OnComplete(f func(JobEvent)) *Jobs {
+	j.hooks.complete = append(j.hooks.complete, f)
+	return j
+}
+
+// setJobOptions stores the runtime options of a job after Do.
+// The options replace the options from the previous Do of the same job,
+// an option which is not set anymore is removed.
+func (c *Config) setJobOptions(name string, o jobOptions) {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	if c.jobOptions == nil {
+		c.jobOptions = make(map[string]*jobOptions)
+	}
+	c.jobOptions[name] = &o
+}
+
+// scheduleOptions returns the calendars and the time windows
+// of a job, they are set by Do
+func (c *Config) scheduleOptions(name string) jobOptions {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	var o jobOptions
+	if opts := c.jobOptions[name]; opts != nil {
+		o.calendars, o.windows = opts.calendars, opts.windows
+	}
+
+	return o
+}
+
+// runOptions returns the global options followed by the options of the job.
+func (c *Config) runOptions(name string) jobOptions {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	var o jobOptions
+	o.hooks.merge(c.hooks)
+	o.middleware = append(o.middleware, c.middleware...)
+	if opts := c.jobOptions[name]; opts != nil {
+		o.hooks.merge(opts.hooks)
+		o.middleware = append(o.middleware, opts.middleware...)
+	}
+
+	return o
+}
+
+// callHooks calls every hook, a panic in a hook is recovered
+// so it doesn't stop the other hooks and the scheduler.
+func callHooks(fs []func(JobEvent), ev JobEvent) {
+	for _, f := range fs {
+		func() {
+			defer func() {
+				recover()
+			}()
+			f(ev)
+		}()
+	}
+}
+
+// safeRun runs the handler and turns a panic into an error.
+func safeRun(ctx context.Context, h Handler, ev JobEvent) (e error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e = fmt.Errorf("Function %s panic: %v", ev.FuncName, r)
+		}
+	}()
+
+	return h(ctx, ev)
+}
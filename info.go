@@ -0,0 +1,219 @@
+package shigoto
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
+	"github.com/KodepandaID/shigoto/pkg/mongodb-connector"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// JobInfo is the state of a registered job
+type JobInfo struct {
+	Name        string          `json:"name"`
+	FuncName    string          `json:"func_name"`
+	Cron        string          `json:"cron"`
+	Description string          `json:"description"`
+	Timezone    string          `json:"timezone"`            // The time zone of the runs, like Asia/Jakarta
+	Calendars   []string        `json:"calendars,omitempty"` // The names of the calendars excluding the dates of the runs
+	Params      [][]interface{} `json:"params"`
+	NextRun     time.Time       `json:"next_run"`
+	LastRun     time.Time       `json:"last_run"`
+	LastManual  bool            `json:"last_manual"`
+	Paused      bool            `json:"paused"`
+	TotalTask   int             `json:"total_task"`
+	TotalRun    int             `json:"total_run"`
+	TotalManual int             `json:"total_manual"`
+	TotalError  int             `json:"total_error"`
+	SuccessRate float64         `json:"success_rate"`
+	ErrorRate   float64         `json:"error_rate"`
+}
+
+// RunInfo is the history of a single run of a job
+type RunInfo struct {
+	RunID       string        `json:"run_id"`
+	JobName     string        `json:"job_name"`
+	Params      []interface{} `json:"params"`
+	ScheduledAt time.Time     `json:"scheduled_at"`
+	StartedAt   time.Time     `json:"started_at"`
+	Duration    time.Duration `json:"duration"`
+	Manual      bool          `json:"manual"`
+	Error       string        `json:"error,omitempty"`
+	Skipped     string        `json:"skipped,omitempty"` // The reason the run was skipped, like the name of a blackout
+	// The runs skipped together and the scheduled time of the last one
+	SkippedRuns     int        `json:"skipped_runs,omitempty"`
+	LastScheduledAt *time.Time `json:"last_scheduled_at,omitempty"`
+}
+
+// List to get all registered jobs
+func (c *Config) List() ([]JobInfo, error) {
+	jobs, e := c.client.GetJobCollection()
+	if e != nil {
+		return nil, e
+	}
+
+	var infos []JobInfo
+	for _, job := range jobs {
+		info, e := c.jobInfo(job)
+		if e != nil {
+			return nil, e
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// Get to get a registered job by the name
+func (c *Config) Get(name string) (JobInfo, error) {
+	job, e := c.client.GetOneJobCollection(name)
+	if e != nil {
+		if e == mongo.ErrNoDocuments {
+			return JobInfo{}, ErrJobNotFound
+		}
+		return JobInfo{}, e
+	}
+
+	return c.jobInfo(job)
+}
+
+// Upcoming to get the jobs that will be running within the window,
+// ordered by the next run.
+func (c *Config) Upcoming(window time.Duration) ([]JobInfo, error) {
+	jobs, e := c.List()
+	if e != nil {
+		return nil, e
+	}
+
+	until := time.Now().Add(window)
+	var infos []JobInfo
+	for _, job := range jobs {
+		if !job.NextRun.IsZero() && !job.NextRun.After(until) {
+			infos = append(infos, job)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].NextRun.Before(infos[j].NextRun)
+	})
+
+	return infos, nil
+}
+
+// History to get the latest runs of a job, newest first
+func (c *Config) History(name string, limit int) ([]RunInfo, error) {
+	runs, e := c.client.GetRuns(name, int64(limit))
+	if e != nil {
+		return nil, e
+	}
+
+	var infos []RunInfo
+	for _, run := range runs {
+		infos = append(infos, RunInfo{
+			RunID:       run.RunID,
+			JobName:     run.JobName,
+			Params:      run.Params,
+			ScheduledAt: run.ScheduledAt.In(c.loc),
+			StartedAt:   run.StartedAt.In(c.loc),
+			Duration:    run.Duration,
+			Manual:      run.Manual,
+			Error:       run.Error,
+			Skipped:     run.Skipped,
+			SkippedRuns: run.SkippedRuns,
+		})
+		if !run.LastScheduledAt.IsZero() {
+			last := run.LastScheduledAt.In(c.loc)
+			infos[len(infos)-1].LastScheduledAt = &last
+		}
+	}
+
+	return infos, nil
+}
+
+func (c *Config) jobInfo(job mongodb.JobCollection) (JobInfo, error) {
+	tasks, e := c.client.GetTasks(job.ID)
+	if e != nil {
+		return JobInfo{}, e
+	}
+
+	// The runs are shown in the time zone of the job
+	expr, _ := cronparser.CompileFields(cronparser.WithZone(job.CronFormat, job.Timezone))
+	loc := c.jobLocation(expr)
+
+	info := JobInfo{
+		Name:        job.JobName,
+		FuncName:    job.FuncName,
+		Cron:        strings.Join(job.CronFormat, " "),
+		Timezone:    loc.String(),
+		NextRun:     job.NextDate.In(loc),
+		LastRun:     job.LastDate.In(loc),
+		LastManual:  job.LastManual,
+		Paused:      job.Paused,
+		TotalTask:   job.TotalTask,
+		TotalRun:    job.TotalRun,
+		TotalManual: job.TotalManual,
+		TotalError:  job.TotalError,
+		SuccessRate: job.SuccessRate,
+		ErrorRate:   job.ErrorRate,
+	}
+	info.Description, _ = cronparser.DescribeLocale(cronparser.WithZone(job.CronFormat, job.Timezone), c.Locale)
+	for _, task := range tasks {
+		info.Params = append(info.Params, task.Params)
+	}
+
+	// The schedule storage is updated before the persistent storage,
+	// so the next run of this instance wins.
+	if scheduled := findTasks(job.JobName); len(scheduled) > 0 {
+		info.NextRun = scheduled[0]["next"].(time.Time).In(loc)
+	}
+	o := c.scheduleOptions(job.JobName)
+	for _, cal := range o.calendars {
+		info.Calendars = append(info.Calendars, cal.Name())
+	}
+	if expr != nil {
+		if next, e := skipExcluded(expr, job.ID.Timestamp(), info.NextRun, o); e == nil {
+			info.NextRun = next
+		}
+	}
+
+	return info, nil
+}
+
+// NextRuns to preview the next n runs of a job in its time zone, the dates
+// excluded by the calendars and the runs out of the time windows of the
+// job are skipped. The blackouts are not applied.
+func (c *Config) NextRuns(name string, n int) ([]time.Time, error) {
+	job, e := c.client.GetOneJobCollection(name)
+	if e != nil {
+		if e == mongo.ErrNoDocuments {
+			return nil, ErrJobNotFound
+		}
+		return nil, e
+	}
+	expr, e := cronparser.CompileFields(cronparser.WithZone(job.CronFormat, job.Timezone))
+	if e != nil {
+		return nil, e
+	}
+
+	var runs []time.Time
+	if expr.IsReboot() {
+		return runs, nil
+	}
+
+	loc := c.jobLocation(expr)
+	from := time.Now()
+	for len(runs) < n {
+		next, e := nextRunOf(c, name, expr, job.ID.Timestamp(), from)
+		if e != nil {
+			if len(runs) == 0 {
+				return nil, e
+			}
+			break
+		}
+		runs = append(runs, next.In(loc))
+		from = next
+	}
+
+	return runs, nil
+}
@@ -1,65 +1,218 @@
 package shigoto
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
 	"github.com/KodepandaID/shigoto/pkg/mongodb-connector"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // Jobs instance
 type Jobs struct {
-	client    *mongodb.Connector
-	parser    *cronparser.Parser
-	JobName   string
-	FuncName  string
-	JobParams []interface{}
-	Cron      []string // Set run a jobs with periodic by second, minute and hour
+	config     *Config
+	client     *mongodb.Connector
+	parser     *cronparser.Parser
+	JobName    string
+	FuncName   string
+	JobParams  []interface{}
+	Cron       []string // Set run a jobs with periodic by second, minute and hour
+	timezone   string   // The time zone of the job, empty for the time zone of the scheduler
+	hooks      hooks
+	middleware []Middleware
+	calendars  []*Calendar // The dates the job doesn't run on
+	windows    []window    // The times of the day the job runs or doesn't run in
+	errs       []error     // The errors of the builder, returned by Do
 }
 
-// Do to run a schedule command
+// BuildError is returned by Do when the job is invalid,
+// it has every error found while building the job.
+type BuildError struct {
+	JobName string
+	Errors  []error
+}
+
+func (b *BuildError) Error() string {
+	msgs := make([]string, len(b.Errors))
+	for i, e := range b.Errors {
+		msgs[i] = e.Error()
+	}
+
+	return fmt.Sprintf("Job %q is invalid: %s", b.JobName, strings.Join(msgs, "; "))
+}
+
+func (j *Jobs) addErr(e error) {
+	j.errs = append(j.errs, e)
+}
+
+// validate returns every error of the builder, the registered
+// function and the cron format as a single BuildError.
+func (j *Jobs) validate() (cronparser.Schedule, error) {
+	errs := j.errs
+
+	f := reflect.ValueOf(FuncStorage[j.FuncName])
+	if !f.IsValid() || f.Kind() != reflect.Func {
+		errs = append(errs, fmt.Errorf("Function %q is not registered", j.FuncName))
+	}
+
+	if zone, _, ok := cronparser.SplitZone(j.Cron); ok && j.timezone != "" && zone != j.timezone {
+		errs = append(errs, fmt.Errorf("The time zone %q conflicts with the cron format %q", j.timezone, strings.Join(j.Cron, " ")))
+	}
+
+	tnow := time.Now()
+	schedule, e := j.parser.SetCurrentTime(tnow).Parse(cronparser.WithZone(j.Cron, j.timezone))
+	if e != nil {
+		errs = append(errs, fmt.Errorf("Cron format %q is invalid: %w", strings.Join(j.Cron, " "), e))
+	} else if len(j.calendars) > 0 || len(j.windows) > 0 {
+		expr, _ := cronparser.CompileFields(cronparser.WithZone(j.Cron, j.timezone))
+		o := jobOptions{calendars: j.calendars, windows: j.windows}
+		if schedule.Next, e = skipExcluded(expr, tnow, schedule.Next, o); e != nil {
+			errs = append(errs, e)
+		}
+	}
+	if warnings, _ := cronparser.Validate(j.Cron); len(warnings) > 0 {
+		j.config.logger().Warn("job rarely runs", "job", j.JobName, "warnings", warnings)
+	}
+
+	if len(errs) > 0 {
+		return schedule, &BuildError{JobName: j.JobName, Errors: errs}
+	}
+
+	return schedule, nil
+}
+
+// Do to run a schedule command. Calling Do again with the same job name
+// updates its cron format, time zone, function and params, the tasks
+// with the other params, like the ones added by AddTask, are removed.
 func (j *Jobs) Do() (id primitive.ObjectID, e error) {
-	schedule, eFatal := j.parser.SetCurrentTime(time.Now()).Parse(j.Cron)
-	if eFatal != nil {
-		panic(eFatal)
+	schedule, e := j.validate()
+	if e != nil {
+		j.config.logger().Error("job rejected", "job", j.JobName, "error", e)
+		return primitive.NilObjectID, e
+	}
+	schedule.Next = schedule.Next.In(j.config.loc)
+	if cronparser.IsReboot(j.Cron) {
+		schedule.Next = time.Now().In(j.config.loc)
 	}
 
 	id, e = j.client.InsertJobCollection(&mongodb.JobCollection{
 		JobName:    j.JobName,
 		FuncName:   j.FuncName,
 		CronFormat: j.Cron,
+		Timezone:   j.zone(),
+		NextDate:   schedule.Next,
 	})
 
-	if id != primitive.NilObjectID && e == nil || id != primitive.NilObjectID && e.Error() == "Jobs is already registered, use the different job name" {
-		e = nil
+	if id != primitive.NilObjectID && e == mongodb.ErrJobRegistered {
+		e = j.upsert(id, schedule)
+	}
+	if e != nil {
+		j.config.logger().Error("storage error", "op", "insert_job", "job", j.JobName, "error", e)
+	}
+	if id != primitive.NilObjectID && e == nil {
+		j.config.logger().Info("job scheduled", "job", j.JobName, "cron", strings.Join(j.Cron, " "), "next", schedule.Next)
 		j.storedTask(id, schedule)
+		j.config.setJobOptions(j.JobName, jobOptions{
+			hooks:      j.hooks,
+			middleware: j.middleware,
+			calendars:  j.calendars,
+			windows:    j.windows,
+		})
 	}
 
 	return id, e
 }
 
-func CallFunc(funcName string) (e error) {
-	f := reflect.ValueOf(FuncStorage[funcName])
-	if !f.IsValid() {
-		return errors.New("Function invalid, check your function register")
+// upsert updates a registered job when its definition in the code
+// drifts from the persisted one, like a changed cron format, function
+// or params. The params of the job are stored as a task by storedTask.
+func (j *Jobs) upsert(id primitive.ObjectID, schedule cronparser.Schedule) error {
+	job, e := j.client.GetOneJobCollection(j.JobName)
+	if e != nil {
+		return e
+	}
+	if e := j.removeOtherParams(id); e != nil {
+		return e
 	}
 
-	values := f.Call([]reflect.Value{})
-	e = HandleErrFunc(values)
+	if job.FuncName == j.FuncName && reflect.DeepEqual(job.CronFormat, j.Cron) && job.Timezone == j.zone() {
+		return nil
+	}
 
-	return e
+	return j.config.reschedule(id, j.JobName, j.FuncName, j.Cron, j.zone(), schedule.Next)
+}
+
+// removeOtherParams removes the tasks of a registered job
+// with params other than the params of the builder
+func (j *Jobs) removeOtherParams(id primitive.ObjectID) error {
+	tasks, e := j.client.GetTasks(id)
+	if e != nil {
+		return e
+	}
+
+	for _, task := range tasks {
+		if sameParams(task.Params, j.JobParams) {
+			continue
+		}
+		if e := j.client.DeleteTask(id, task.Params...); e != nil && e != mongo.ErrNoDocuments {
+			return e
+		}
+		j.config.logger().Info("job params removed", "job", j.JobName, "params", task.Params)
+	}
+
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	for _, task := range removeTasks(j.JobName) {
+		if sameParams(task["params"].([]interface{}), j.JobParams) {
+			scheduleTask(task)
+		}
+	}
+
+	return nil
+}
+
+// zone returns the time zone of the job, the time zone of
+// `CRON_TZ=` wins over the one set by Timezone
+func (j *Jobs) zone() string {
+	if zone, _, ok := cronparser.SplitZone(j.Cron); ok {
+		return zone
+	}
+
+	return j.timezone
+}
+
+func CallFunc(funcName string) (e error) {
+	return callFunc(context.Background(), funcName, nil)
 }
 
 func CallFuncWithParams(funcName string, params []interface{}) (e error) {
+	return callFunc(context.Background(), funcName, params)
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// callFunc calls the registered function with the params.
+// When the first argument of the function is a context.Context,
+// the ctx is passed before the params.
+func callFunc(ctx context.Context, funcName string, params []interface{}) (e error) {
 	f := reflect.ValueOf(FuncStorage[funcName])
+	if !f.IsValid() {
+		return errors.New("Function invalid, check your function register")
+	}
 
-	in := make([]reflect.Value, len(params))
-	for k, param := range params {
-		in[k] = reflect.ValueOf(param)
+	var in []reflect.Value
+	if f.Type().NumIn() > 0 && f.Type().In(0) == contextType {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+	for _, param := range params {
+		in = append(in, convertParam(param, argType(f.Type(), len(in))))
 	}
 
 	values := f.Call(in)
@@ -68,6 +221,41 @@ func CallFuncWithParams(funcName string, params []interface{}) (e error) {
 	return e
 }
 
+// argType returns the type of the i-th argument of the function,
+// it's nil when the function has no such argument
+func argType(f reflect.Type, i int) reflect.Type {
+	switch {
+	case f.IsVariadic() && i >= f.NumIn()-1:
+		return f.In(f.NumIn() - 1).Elem()
+	case i < f.NumIn():
+		return f.In(i)
+	}
+
+	return nil
+}
+
+// convertParam converts a param to the type of the argument, like the
+// float64 of a JSON number or the int32 of a BSON number to an int.
+// A number is only converted when its value is kept, a nil param
+// is the zero value of the argument.
+func convertParam(param interface{}, t reflect.Type) reflect.Value {
+	v := reflect.ValueOf(param)
+	switch {
+	case t == nil:
+		return v
+	case !v.IsValid():
+		return reflect.Zero(t)
+	case v.Type().AssignableTo(t):
+		return v
+	case isNumber(v.Kind()) && isNumber(t.Kind()) && v.Type().ConvertibleTo(t):
+		if c := v.Convert(t); reflect.DeepEqual(normalParam(c.Interface()), normalParam(param)) {
+			return c
+		}
+	}
+
+	return v
+}
+
 func HandleErrFunc(values []reflect.Value) (e error) {
 	for i, val := range values {
 		if val.Type().String() == "error" && !val.IsNil() {
@@ -79,39 +267,17 @@ func HandleErrFunc(values []reflect.Value) (e error) {
 }
 
 func (j *Jobs) storedTask(id primitive.ObjectID, schedule cronparser.Schedule) {
-	if ScheduleStorage[schedule.Next.String()] == nil {
-		ScheduleStorage[schedule.Next.String()] = []map[string]interface{}{
-			{
-				"id":        id.Hex(),
-				"job_name":  j.JobName,
-				"func_name": j.FuncName,
-				"params":    j.JobParams,
-				"cron":      j.Cron,
-			},
-		}
-		j.client.InsertTask(id, j.JobParams...)
-	} else {
-		ss := ScheduleStorage[schedule.Next.String()].([]map[string]interface{})
-
-		var sameParams bool
-		for _, task := range ss {
-			// check if the task having the same params,
-			// if they have the same params, it will be ignored.
-			if reflect.DeepEqual(task["params"].([]interface{}), j.JobParams) && task["job_name"].(string) == j.JobName {
-				sameParams = true
-			}
-		}
+	storageLock.Lock()
+	defer storageLock.Unlock()
 
-		if !sameParams {
-			ss = append(ss, map[string]interface{}{
-				"id":        id.Hex(),
-				"job_name":  j.JobName,
-				"func_name": j.FuncName,
-				"params":    j.JobParams,
-				"cron":      j.Cron,
-			})
-			ScheduleStorage[schedule.Next.String()] = ss
-			j.client.InsertTask(id, j.JobParams...)
+	task, e := newTask(id.Hex(), j.JobName, j.FuncName, j.JobParams, cronparser.WithZone(j.Cron, j.timezone), schedule.Next)
+	if e != nil {
+		j.config.logger().Error("job cannot be scheduled", "job", j.JobName, "error", e)
+		return
+	}
+	if scheduleTask(task) {
+		if e := j.client.InsertTask(id, j.JobParams...); e != nil {
+			j.config.logger().Error("storage error", "op", "insert_task", "job", j.JobName, "error", e)
 		}
 	}
 }
@@ -0,0 +1,102 @@
+package shigoto
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger receives the leveled and structured logs of the scheduler.
+// The keysAndValues are pairs of a key and its value.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// Level is the minimum level written by the standard logger
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelName = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+type stdLogger struct {
+	l     *log.Logger
+	level Level
+}
+
+// NewStdLogger to create a Logger which writes to the log.Logger
+// in a `LEVEL message key=value` format.
+// The standard logger is used when l is nil.
+func NewStdLogger(l *log.Logger, level Level) Logger {
+	if l == nil {
+		l = log.New(log.Writer(), log.Prefix(), log.Flags())
+	}
+
+	return &stdLogger{l: l, level: level}
+}
+
+func (s *stdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.write(LevelDebug, msg, keysAndValues)
+}
+
+func (s *stdLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.write(LevelInfo, msg, keysAndValues)
+}
+
+func (s *stdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	s.write(LevelWarn, msg, keysAndValues)
+}
+
+func (s *stdLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.write(LevelError, msg, keysAndValues)
+}
+
+func (s *stdLogger) write(level Level, msg string, keysAndValues []interface{}) {
+	if level < s.level {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(levelName[level])
+	b.WriteString(" ")
+	b.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 < len(keysAndValues) {
+			fmt.Fprintf(&b, " %v=%v", keysAndValues[i], keysAndValues[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", keysAndValues[i])
+		}
+	}
+
+	s.l.Print(b.String())
+}
+
+type nopLogger struct{}
+
+// NopLogger is a Logger which discards every log
+var NopLogger Logger = nopLogger{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+var defaultLogger = NewStdLogger(nil, LevelInfo)
+
+// logger returns the Logger of the config, or the standard
+// logger at the info level when it isn't set.
+func (c *Config) logger() Logger {
+	if c.Logger == nil {
+		return defaultLogger
+	}
+
+	return c.Logger
+}
@@ -0,0 +1,52 @@
+package shigoto
+
+import (
+	"time"
+)
+
+// Metrics receives the measurements of every run and storage
+// operation. The core doesn't depend on a metrics library,
+// pkg/prometheus-exporter implements it for Prometheus.
+type Metrics interface {
+	// ObserveRun is called after every run, lag is the actual
+	// start of the run minus its scheduled time.
+	ObserveRun(job string, duration, lag time.Duration, e error)
+	// ObserveStorage is called after every operation of the persistent storage.
+	ObserveStorage(op string, duration time.Duration, e error)
+}
+
+// State is a snapshot of the schedule storage
+type State struct {
+	NextRuns   map[string]time.Time // The next run of every registered job
+	QueueDepth int                  // The runs which are due and not finished yet
+}
+
+// State to get the next run of every job and the queue depth
+func (c *Config) State() State {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	s := State{
+		NextRuns:   make(map[string]time.Time),
+		QueueDepth: c.queued,
+	}
+	for _, jobs := range ScheduleStorage {
+		for _, task := range jobs.([]map[string]interface{}) {
+			name := task["job_name"].(string)
+			next := task["next"].(time.Time)
+			if t, ok := s.NextRuns[name]; !ok || next.Before(t) {
+				s.NextRuns[name] = next
+			}
+		}
+	}
+
+	return s
+}
+
+// observeStorage passes the storage operations to the Metrics of the config,
+// so the Metrics can be set after the instance is created.
+func (c *Config) observeStorage(op string, duration time.Duration, e error) {
+	if m := c.Metrics; m != nil {
+		m.ObserveStorage(op, duration, e)
+	}
+}
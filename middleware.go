@@ -0,0 +1,114 @@
+package shigoto
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Handler runs a job with the event of the run
+type Handler func(ctx context.Context, ev JobEvent) error
+
+// Middleware wraps a Handler to run code around the job,
+// like tracing, metrics or a distributed lock.
+type Middleware func(next Handler) Handler
+
+// Use to add middleware around every job.
+// The global middleware wraps the middleware of the job.
+func (c *Config) Use(mw ...Middleware) *Config {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	c.middleware = append(c.middleware, mw...)
+	return c
+}
+
+// Use to add middleware around the job
+func (j *Jobs) Use(mw ...Middleware) *Jobs {
+	j.middleware = append(j.middleware, mw...)
+	return j
+}
+
+// invoke is the innermost handler, it calls the registered function.
+func invoke(ctx context.Context, ev JobEvent) error {
+	return callFunc(ctx, ev.FuncName, ev.Params)
+}
+
+// chain wraps the handler with the middleware,
+// the first middleware is the outermost.
+func chain(h Handler, mw []Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+
+	return h
+}
+
+// Logging logs the start and the result of every run.
+// The standard logger at the info level is used when l is nil.
+func Logging(l Logger) Middleware {
+	if l == nil {
+		l = defaultLogger
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, ev JobEvent) error {
+			l.Info("job started", "job", ev.JobName, "attempt", ev.Attempt, "manual", ev.Manual)
+			started := time.Now()
+
+			e := next(ctx, ev)
+			if e != nil {
+				l.Error("job failed", "job", ev.JobName, "duration", time.Since(started), "error", e)
+			} else {
+				l.Info("job done", "job", ev.JobName, "duration", time.Since(started))
+			}
+
+			return e
+		}
+	}
+}
+
+// Recovery turns a panic in the next handler into an error
+func Recovery() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, ev JobEvent) (e error) {
+			defer func() {
+				if r := recover(); r != nil {
+					e = fmt.Errorf("Function %s panic: %v", ev.FuncName, r)
+				}
+			}()
+
+			return next(ctx, ev)
+		}
+	}
+}
+
+// Timeout returns an error when the next handler doesn't finish within d.
+// The context passed to the job is cancelled at the timeout, a job
+// function which doesn't accept a context.Context keeps running
+// in the background until it returns.
+func Timeout(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, ev JobEvent) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- fmt.Errorf("Function %s panic: %v", ev.FuncName, r)
+					}
+				}()
+				done <- next(ctx, ev)
+			}()
+
+			select {
+			case e := <-done:
+				return e
+			case <-ctx.Done():
+				return fmt.Errorf("Job %s timeout after %s", ev.JobName, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/KodepandaID/shigoto"
+	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
+)
+
+// Scheduler is the part of shigoto.Config used by the handler
+type Scheduler interface {
+	List() ([]shigoto.JobInfo, error)
+	Get(name string) (shigoto.JobInfo, error)
+	History(name string, limit int) ([]shigoto.RunInfo, error)
+	Pause(name string) error
+	Resume(name string) error
+	Trigger(name string, overrideParams ...interface{}) error
+	Reschedule(name, cron string) error
+	Delete(name string) error
+	AddTask(name string, params ...interface{}) error
+	RemoveTask(name string, params ...interface{}) error
+}
+
+// Handler serves the JSON admin API of a scheduler.
+// It can be mounted on any router with http.StripPrefix:
+//
+//	GET    /jobs                  list the jobs and their stats
+//	GET    /jobs/{name}?runs=20   get a job with its recent runs
+//	DELETE /jobs/{name}           delete a job
+//	POST   /jobs/{name}/pause     pause a job
+//	POST   /jobs/{name}/resume    resume a job
+//	POST   /jobs/{name}/trigger   run a job now, {"params": [...]} is optional
+//	PUT    /jobs/{name}/schedule  change the schedule, {"cron": "* * * * *"}
+//	POST   /jobs/{name}/tasks     add a params, {"params": [...]}
+//	DELETE /jobs/{name}/tasks     remove a params, {"params": [...]}
+type Handler struct {
+	s Scheduler
+}
+
+type paramsBody struct {
+	Params []interface{} `json:"params"`
+}
+
+type scheduleBody struct {
+	Cron string `json:"cron"`
+}
+
+type jobResponse struct {
+	Job  shigoto.JobInfo   `json:"job"`
+	Runs []shigoto.RunInfo `json:"runs"`
+}
+
+// New to create the admin API handler of the scheduler
+func New(s Scheduler) *Handler {
+	return &Handler{s: s}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	if parts[0] != "jobs" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, errors.New("Not found"))
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("Method not allowed"))
+			return
+		}
+		h.list(w, r)
+		return
+	}
+
+	name, e := url.PathUnescape(parts[1])
+	if e != nil || name == "" {
+		writeError(w, http.StatusBadRequest, errors.New("The job's name is invalid"))
+		return
+	}
+
+	var action string
+	if len(parts) == 3 {
+		action = parts[2]
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.get(w, r, name)
+	case action == "" && r.Method == http.MethodDelete:
+		writeResult(w, http.StatusOK, h.s.Delete(name))
+	case action == "pause" && r.Method == http.MethodPost:
+		writeResult(w, http.StatusOK, h.s.Pause(name))
+	case action == "resume" && r.Method == http.MethodPost:
+		writeResult(w, http.StatusOK, h.s.Resume(name))
+	case action == "trigger" && r.Method == http.MethodPost:
+		var body paramsBody
+		if !decode(w, r, &body, true) {
+			return
+		}
+		writeResult(w, http.StatusAccepted, h.s.Trigger(name, body.Params...))
+	case action == "schedule" && r.Method == http.MethodPut:
+		var body scheduleBody
+		if !decode(w, r, &body, false) {
+			return
+		}
+		writeResult(w, http.StatusOK, h.s.Reschedule(name, body.Cron))
+	case action == "tasks" && r.Method == http.MethodPost:
+		var body paramsBody
+		if !decode(w, r, &body, false) {
+			return
+		}
+		writeResult(w, http.StatusCreated, h.s.AddTask(name, body.Params...))
+	case action == "tasks" && r.Method == http.MethodDelete:
+		var body paramsBody
+		if !decode(w, r, &body, false) {
+			return
+		}
+		writeResult(w, http.StatusOK, h.s.RemoveTask(name, body.Params...))
+	case action == "" || action == "pause" || action == "resume" || action == "trigger" ||
+		action == "schedule" || action == "tasks":
+		writeError(w, http.StatusMethodNotAllowed, errors.New("Method not allowed"))
+	default:
+		writeError(w, http.StatusNotFound, errors.New("Not found"))
+	}
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	jobs, e := h.s.List()
+	if e != nil {
+		writeError(w, statusOf(e), e)
+		return
+	}
+	if jobs == nil {
+		jobs = []shigoto.JobInfo{}
+	}
+
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, name string) {
+	limit := 20
+	if v := r.URL.Query().Get("runs"); v != "" {
+		n, e := strconv.Atoi(v)
+		if e != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("The runs query must be a positive number"))
+			return
+		}
+		limit = n
+	}
+
+	job, e := h.s.Get(name)
+	if e != nil {
+		writeError(w, statusOf(e), e)
+		return
+	}
+
+	runs := []shigoto.RunInfo{}
+	if limit > 0 {
+		if runs, e = h.s.History(name, limit); e != nil {
+			writeError(w, statusOf(e), e)
+			return
+		}
+		if runs == nil {
+			runs = []shigoto.RunInfo{}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, jobResponse{Job: job, Runs: runs})
+}
+
+// decode reads the JSON body, an empty body is allowed when optional is true.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}, optional bool) bool {
+	e := json.NewDecoder(r.Body).Decode(v)
+	if e == nil || optional && e == io.EOF {
+		return true
+	}
+
+	writeError(w, http.StatusBadRequest, errors.New("The request body is invalid JSON"))
+	return false
+}
+
+// statusOf maps the errors of the scheduler to a HTTP status
+func statusOf(e error) int {
+	var build *shigoto.BuildError
+	var invalid *cronparser.ValidationError
+	switch {
+	case errors.Is(e, shigoto.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.As(e, &build), errors.As(e, &invalid):
+		return http.StatusBadRequest
+	case errors.Is(e, shigoto.ErrTaskRegistered), errors.Is(e, shigoto.ErrTaskNotFound),
+		errors.Is(e, shigoto.ErrExcluded), errors.Is(e, cronparser.ErrNeverMatches):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+func writeResult(w http.ResponseWriter, status int, e error) {
+	if e != nil {
+		writeError(w, statusOf(e), e)
+		return
+	}
+
+	writeJSON(w, status, map[string]string{"status": "ok"})
+}
+
+func writeError(w http.ResponseWriter, status int, e error) {
+	writeJSON(w, status, map[string]string{"error": e.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,387 @@
+package cronparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Locale is the language of a description
+type Locale string
+
+// The supported languages, a description is in English
+// when the locale is not supported
+const (
+	English    Locale = "en"
+	Indonesian Locale = "id"
+)
+
+// phrases are the words of a description in a language,
+// the formats are filled with fmt.Sprintf
+type phrases struct {
+	at, and, or        string
+	through, between   string
+	startingAt, every  string
+	everyOne, units    [6]string
+	fieldOne, fieldAll [6]string // A field of a value and a field of many values
+	label              [6]string // A value of a field, like `minute 5`
+	pastHour           string
+	lastDay            string
+	lastWeekday        string
+	nearestWeekday     string
+	lastOf, nthOf      string
+	ordinals           [6]string
+	months             [13]string
+	weekdays           [7]string
+	reboot, interval   string
+	zone               string
+}
+
+var locales = map[Locale]phrases{
+	English: {
+		at:         "at %s",
+		and:        "and",
+		or:         "or",
+		through:    "%s through %s",
+		between:    "between %s and %s",
+		startingAt: "starting at %s",
+		every:      "every %d %s",
+		everyOne:   [6]string{"every second", "every minute", "every hour", "every day", "every month", "every day"},
+		units:      [6]string{"seconds", "minutes", "hours", "days", "months", "days"},
+		fieldOne: [6]string{
+			"at second %s", "at minute %s", "at hour %s", "on day %s of the month", "only in %s", "only on %s",
+		},
+		fieldAll: [6]string{
+			"at seconds %s", "at minutes %s", "at hours %s", "on days %s of the month", "only in %s", "only on %s",
+		},
+		label:          [6]string{"second %s", "minute %s", "%s", "day %s", "%s", "%s"},
+		pastHour:       "at %d minutes past the hour",
+		lastDay:        "on the last day of the month",
+		lastWeekday:    "on the last weekday of the month",
+		nearestWeekday: "on the weekday nearest day %d of the month",
+		lastOf:         "on the last %s of the month",
+		nthOf:          "on the %[1]s %[2]s of the month",
+		ordinals:       [6]string{"", "first", "second", "third", "fourth", "fifth"},
+		months: [13]string{"", "January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		weekdays: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		reboot:   "once every time the scheduler starts",
+		interval: "every %s",
+		zone:     "in the %s time zone",
+	},
+	Indonesian: {
+		at:         "pada pukul %s",
+		and:        "dan",
+		or:         "atau",
+		through:    "%s sampai %s",
+		between:    "antara pukul %s dan %s",
+		startingAt: "mulai %s",
+		every:      "setiap %d %s",
+		everyOne:   [6]string{"setiap detik", "setiap menit", "setiap jam", "setiap hari", "setiap bulan", "setiap hari"},
+		units:      [6]string{"detik", "menit", "jam", "hari", "bulan", "hari"},
+		fieldOne: [6]string{
+			"pada detik %s", "pada menit %s", "pada jam %s", "pada tanggal %s", "hanya pada bulan %s", "hanya pada hari %s",
+		},
+		fieldAll: [6]string{
+			"pada detik %s", "pada menit %s", "pada jam %s", "pada tanggal %s", "hanya pada bulan %s", "hanya pada hari %s",
+		},
+		label:          [6]string{"detik %s", "menit %s", "%s", "tanggal %s", "bulan %s", "hari %s"},
+		pastHour:       "pada menit ke-%d setiap jam",
+		lastDay:        "pada hari terakhir setiap bulan",
+		lastWeekday:    "pada hari kerja terakhir setiap bulan",
+		nearestWeekday: "pada hari kerja terdekat dengan tanggal %d",
+		lastOf:         "pada hari %s terakhir setiap bulan",
+		nthOf:          "pada hari %[2]s %[1]s setiap bulan",
+		ordinals:       [6]string{"", "pertama", "kedua", "ketiga", "keempat", "kelima"},
+		months: [13]string{"", "Januari", "Februari", "Maret", "April", "Mei", "Juni",
+			"Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		weekdays: [7]string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+		reboot:   "sekali setiap kali penjadwal dimulai",
+		interval: "setiap %s",
+		zone:     "di zona waktu %s",
+	},
+}
+
+// Describe turns a cron format, a macro or a modifier into an English
+// sentence, like "At 09:30, Monday through Friday" for `30 9 * * 1-5`.
+func Describe(expr []string) (string, error) {
+	return DescribeLocale(expr, English)
+}
+
+// DescribeLocale turns a cron format into a sentence in the language
+// of the locale, like "Pada pukul 09:30, Senin sampai Jumat" in Indonesian.
+func DescribeLocale(expr []string, locale Locale) (string, error) {
+	if e := validate(expr); e != nil {
+		return "", e
+	}
+	l, ok := locales[locale]
+	if !ok {
+		l = locales[English]
+	}
+
+	zone, expr, ok := SplitZone(expr)
+	sentence, e := l.describe(expr)
+	if e != nil || !ok {
+		return sentence, e
+	}
+
+	return sentence + ", " + fmt.Sprintf(l.zone, zone), nil
+}
+
+// describe describes a validated cron format without its time zone
+func (l phrases) describe(expr []string) (string, error) {
+	if m, ok := macros[strings.ToLower(expr[0])]; ok {
+		expr = m
+	}
+	switch strings.ToLower(expr[0]) {
+	case "@reboot":
+		return capitalize(l.reboot), nil
+	case "@every":
+		return capitalize(fmt.Sprintf(l.interval, expr[1])), nil
+	}
+
+	var p Parser
+	d, e := p.exprParse(expr)
+	if e != nil {
+		return "", e
+	}
+	f := make([]string, len(d))
+	for i := range d {
+		f[i] = d[i].expr
+	}
+
+	parts := l.timeOf(f)
+	dayMonth, weekday := l.field(f, fieldDayMonth), l.field(f, fieldWeekday)
+	if dayMonth != "" && weekday != "" {
+		parts = append(parts, dayMonth+" "+l.or+" "+weekday)
+	} else {
+		parts = append(parts, dayMonth, weekday)
+	}
+	parts = append(parts, l.field(f, fieldMonth))
+
+	var sentence []string
+	for _, part := range parts {
+		if part != "" {
+			sentence = append(sentence, part)
+		}
+	}
+
+	return capitalize(strings.Join(sentence, ", ")), nil
+}
+
+// timeOf describes the second, the minute and the hour, a few
+// fixed times are described as the clocks like "at 09:00 and 17:00"
+func (l phrases) timeOf(f []string) []string {
+	second, minute, hour := f[fieldSecond], f[fieldMinute], f[fieldHour]
+
+	seconds, okSecond := plainValues(second)
+	minutes, okMinute := plainValues(minute)
+	hours, okHour := plainValues(hour)
+	if okSecond && okMinute && okHour && len(seconds) == 1 && len(minutes)*len(hours) <= 4 {
+		var clocks []string
+		for _, h := range hours {
+			for _, m := range minutes {
+				clocks = append(clocks, clock(h, m, seconds[0]))
+			}
+		}
+		return []string{fmt.Sprintf(l.at, l.list(clocks))}
+	}
+
+	var parts []string
+	switch second {
+	case "0":
+	case "*":
+		parts = append(parts, l.everyOne[fieldSecond])
+	default:
+		parts = append(parts, l.field(f, fieldSecond))
+	}
+
+	switch {
+	case minute == "*":
+		if second == "0" {
+			parts = append(parts, l.everyOne[fieldMinute])
+		}
+	case okMinute && len(minutes) == 1 && hour == "*":
+		if minutes[0] == 0 && second == "0" {
+			parts = append(parts, l.everyOne[fieldHour])
+		} else {
+			parts = append(parts, fmt.Sprintf(l.pastHour, minutes[0]))
+		}
+	case minute == "0" && second == "0":
+		if !strings.Contains(hour, "/") {
+			parts = append(parts, l.everyOne[fieldHour])
+		}
+	default:
+		parts = append(parts, l.field(f, fieldMinute))
+	}
+
+	return append(parts, l.field(f, fieldHour))
+}
+
+// field describes the entries of a field, the modifiers and
+// the steps are described one by one
+func (l phrases) field(f []string, i int) string {
+	expr := f[i]
+	if expr == "*" {
+		return ""
+	}
+
+	var parts, entries []string
+	for _, entry := range strings.Split(expr, ",") {
+		if modifier := l.modifier(i, entry); modifier != "" {
+			parts = append(parts, modifier)
+		} else if strings.Contains(entry, "/") {
+			parts = append(parts, l.step(i, entry))
+		} else {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) > 0 {
+		parts = append([]string{l.entries(i, entries)}, parts...)
+	}
+
+	return l.list(parts)
+}
+
+// entries describes the values and the ranges of a field
+func (l phrases) entries(i int, entries []string) string {
+	if len(entries) == 1 {
+		if first, last, ok := bounds(entries[0]); ok && (i == fieldHour || first != last && i >= fieldMonth) {
+			if i == fieldHour {
+				return fmt.Sprintf(l.between, clock(first, 0, 0), clock(last, 59, 0))
+			}
+			return fmt.Sprintf(l.through, l.value(i, first), l.value(i, last))
+		}
+	}
+
+	format := l.fieldOne[i]
+	var values []string
+	for _, entry := range entries {
+		first, last, _ := bounds(entry)
+		if first == last {
+			values = append(values, l.value(i, first))
+			continue
+		}
+		values = append(values, fmt.Sprintf(l.through, l.value(i, first), l.value(i, last)))
+		format = l.fieldAll[i]
+	}
+	if len(values) > 1 {
+		format = l.fieldAll[i]
+	}
+
+	return fmt.Sprintf(format, l.list(values))
+}
+
+// step describes an entry with a step: `*/2`, `1/2` or `1-5/2`
+func (l phrases) step(i int, entry string) string {
+	base := entry[:strings.Index(entry, "/")]
+	n, _ := strconv.Atoi(entry[len(base)+1:])
+
+	every := l.everyOne[i]
+	if n > 1 {
+		every = fmt.Sprintf(l.every, n, l.units[i])
+	}
+
+	first, last, _ := bounds(base)
+	switch {
+	case base == "*":
+		return every
+	case first == last:
+		return every + ", " + fmt.Sprintf(l.startingAt, fmt.Sprintf(l.label[i], l.value(i, first)))
+	default:
+		return every + ", " + l.entries(i, []string{base})
+	}
+}
+
+// modifier describes the L, W or # modifier of the day of month
+// or the weekday, it's empty for other entries
+func (l phrases) modifier(i int, entry string) string {
+	switch {
+	case i == fieldDayMonth && entry == "l":
+		return l.lastDay
+	case i == fieldDayMonth && entry == "lw":
+		return l.lastWeekday
+	case i == fieldDayMonth && strings.HasSuffix(entry, "w"):
+		day, _ := strconv.Atoi(strings.TrimSuffix(entry, "w"))
+		return fmt.Sprintf(l.nearestWeekday, day)
+	case i == fieldWeekday && strings.HasSuffix(entry, "l"):
+		weekday, _ := strconv.Atoi(strings.TrimSuffix(entry, "l"))
+		return fmt.Sprintf(l.lastOf, l.weekdays[weekday%7])
+	case i == fieldWeekday && strings.Contains(entry, "#"):
+		weekday, _ := strconv.Atoi(entry[:strings.Index(entry, "#")])
+		nth, _ := strconv.Atoi(entry[strings.Index(entry, "#")+1:])
+		return fmt.Sprintf(l.nthOf, l.ordinals[nth], l.weekdays[weekday%7])
+	}
+
+	return ""
+}
+
+// value is a value of a field as a word, like the name of a month
+func (l phrases) value(i, v int) string {
+	switch i {
+	case fieldHour:
+		return clock(v, 0, 0)
+	case fieldMonth:
+		return l.months[v]
+	case fieldWeekday:
+		return l.weekdays[v%7]
+	}
+
+	return strconv.Itoa(v)
+}
+
+// list joins the items like "a, b and c"
+func (l phrases) list(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+
+	return strings.Join(items[:len(items)-1], ", ") + " " + l.and + " " + items[len(items)-1]
+}
+
+// plainValues returns the values of a field which is a list of values
+func plainValues(expr string) ([]int, bool) {
+	var values []int
+	for _, entry := range strings.Split(expr, ",") {
+		v, e := strconv.Atoi(entry)
+		if e != nil {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+
+	return values, true
+}
+
+// bounds returns the first and the last value of a value or a range
+func bounds(entry string) (first, last int, ok bool) {
+	values := strings.SplitN(entry, "-", 2)
+	first, e := strconv.Atoi(values[0])
+	if e != nil {
+		return 0, 0, false
+	}
+	last = first
+	if len(values) == 2 {
+		if last, e = strconv.Atoi(values[1]); e != nil {
+			return 0, 0, false
+		}
+	}
+
+	return first, last, true
+}
+
+func clock(hour, minute, second int) string {
+	if second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hour, minute, second)
+	}
+
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,117 @@
+package cronparser
+
+import (
+	"strings"
+	"time"
+)
+
+// Expression is a cron format compiled once, the values of its fields
+// are bitsets. Next doesn't allocate, an Expression is safe for
+// concurrent use.
+type Expression struct {
+	source string
+	fields []cronField    // The compiled fields, nil for a macro without fields
+	every  time.Duration  // The interval of @every
+	reboot bool           // @reboot never runs on a schedule
+	loc    *time.Location // The time zone of `CRON_TZ=`, nil for the location of the given time
+}
+
+// Compile compiles a cron format like `*/15 9-17 * * MON-FRI`, a macro like
+// `@daily`, `@every 1h30m` or `@reboot`. A cron format can start with its
+// time zone like `CRON_TZ=America/New_York 0 9 * * *`. The error is a
+// *ValidationError when the cron format is incorrect.
+func Compile(expr string) (*Expression, error) {
+	return CompileFields(strings.Fields(expr))
+}
+
+// CompileFields compiles a cron format split into its fields
+func CompileFields(expr []string) (*Expression, error) {
+	if e := validate(expr); e != nil {
+		return nil, e
+	}
+
+	x := &Expression{source: strings.Join(expr, " ")}
+	if zone, fields, ok := SplitZone(expr); ok {
+		x.loc, _ = time.LoadLocation(zone)
+		expr = fields
+	}
+	if m, ok := macros[strings.ToLower(expr[0])]; ok {
+		expr = m
+	}
+	switch strings.ToLower(expr[0]) {
+	case "@reboot":
+		x.reboot = true
+	case "@every":
+		x.every, _ = time.ParseDuration(expr[1])
+	default:
+		var p Parser
+		d, e := p.exprParse(expr)
+		if e != nil {
+			return nil, e
+		}
+		x.fields = d
+	}
+
+	return x, nil
+}
+
+// Next returns the first activation time after from in the time zone of
+// the expression or else the location of from, it's zero when there is none.
+// The intervals of @every start at from, see NextSince.
+func (x *Expression) Next(from time.Time) time.Time {
+	return x.NextSince(from, from)
+}
+
+// NextSince is Next with the intervals of @every starting at the anchor,
+// like the registration time of a job
+func (x *Expression) NextSince(anchor, from time.Time) time.Time {
+	if x.loc != nil {
+		from = from.In(x.loc)
+	}
+
+	switch {
+	case x.reboot:
+		return time.Time{}
+	case x.every > 0:
+		return nextInterval(anchor, from, x.every)
+	}
+
+	return nextTime(x.fields, from)
+}
+
+// IsReboot reports if the expression is @reboot,
+// it runs once every time the scheduler starts
+func (x *Expression) IsReboot() bool {
+	return x.reboot
+}
+
+// Location returns the time zone of `CRON_TZ=`,
+// it's nil when the expression has no time zone
+func (x *Expression) Location() *time.Location {
+	return x.loc
+}
+
+// String returns the cron format as it was compiled,
+// the fields are joined by a space
+func (x *Expression) String() string {
+	return x.source
+}
+
+// Equal reports if both expressions are the same cron format, like
+// `0 9 * * MON-FRI` and `0 9 * * 1-5`, or `@daily` and `0 0 * * *`
+func (x *Expression) Equal(o *Expression) bool {
+	if x == nil || o == nil {
+		return x == o
+	}
+	if x.reboot != o.reboot || x.every != o.every || len(x.fields) != len(o.fields) ||
+		(x.loc == nil) != (o.loc == nil) || x.loc != nil && x.loc.String() != o.loc.String() {
+		return false
+	}
+	for i := range x.fields {
+		if !x.fields[i].equal(&o.fields[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,7 +1,9 @@
 package cronparser
 
 import (
-	"math"
+	"errors"
+	"fmt"
+	"math/bits"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +13,7 @@ type Parser struct {
 	loc         *time.Location
 	Timezone    string
 	SetTime     time.Time
+	Anchor      time.Time // The start of the intervals of @every, the current time is used when it's zero
 	currentTime time.Time
 }
 
@@ -19,21 +22,72 @@ type Schedule struct {
 	// Next returns the next activation time, later than the given time.
 	// Next is invoked initially, and then each time the job is run.
 	Next time.Time
+
+	expr   *Expression
+	anchor time.Time // The start of the intervals of @every
+	loc    *time.Location
+}
+
+// cronField is the set of allowed values of a field
+// with the modifiers of the day of month and the weekday
+type cronField struct {
+	expr        string
+	bits        uint64   // The allowed values, the bit n is the value n
+	star        bool     // The field starts with `*`, see matchDay
+	lastDay     bool     // `L`, the last day of the month
+	lastWeekday bool     // `LW`, the last weekday of the month
+	nearest     uint32   // `15W`, the bit n is the weekday nearest to the day n
+	lastOf      uint8    // `5L`, the bit n is the last of the weekday n in the month
+	nthOf       [7]uint8 // `2#2`, the bit n is the nth of the weekday in the month
+}
+
+func (f *cronField) has(v int) bool {
+	return f.bits&(1<<uint(v)) != 0
+}
+
+// nextValue returns the first value of the field from v
+func (f *cronField) nextValue(v int) (int, bool) {
+	rest := f.bits >> uint(v)
+	if rest == 0 {
+		return 0, false
+	}
+
+	return v + bits.TrailingZeros64(rest), true
 }
 
-type cronDirective struct {
-	expr     string
-	kind     int
-	first    int
-	last     int
-	step     int
-	stepSpan []int
+// equal reports if both fields have the same values and modifiers
+func (f *cronField) equal(o *cronField) bool {
+	return f.bits == o.bits && f.star == o.star &&
+		f.lastDay == o.lastDay && f.lastWeekday == o.lastWeekday &&
+		f.nearest == o.nearest && f.lastOf == o.lastOf && f.nthOf == o.nthOf
 }
 
+// The index of the fields, a cron format without the seconds
+// field runs at the second 0
+const (
+	fieldSecond = iota
+	fieldMinute
+	fieldHour
+	fieldDayMonth
+	fieldMonth
+	fieldWeekday
+)
+
+// The first and the last value of the fields
+var (
+	fieldMin = []int{0, 0, 0, 1, 1, 0}
+	fieldMax = []int{59, 59, 23, 31, 12, 7}
+)
+
+// yearLimit is how far the next time is searched,
+// a format like `0 0 30 2 *` never matches.
+const yearLimit = 30
+
 // New to create a new instance
 func New(p *Parser) Parser {
 	return Parser{
 		Timezone:    p.Timezone,
+		Anchor:      p.Anchor,
 		currentTime: p.SetTime,
 	}
 }
@@ -45,277 +99,336 @@ func (p *Parser) SetCurrentTime(t time.Time) *Parser {
 	return p
 }
 
-// Parse to parsing cron format
+// Parse to parsing cron format. A cron format can be a macro like
+// `@daily`, `@every 1h30m` or `@reboot`, the Next of @reboot is zero.
+// The cron format is compiled for every call, see Expression to compile
+// it once.
 func (p *Parser) Parse(expr []string) (Schedule, error) {
 	var s Schedule
-	if e := validate(expr); e != nil {
+	x, e := CompileFields(expr)
+	if e != nil {
 		return s, e
 	}
 	p.loc, _ = time.LoadLocation(p.Timezone)
 
-	if p.Timezone != "" {
-		if p.currentTime.IsZero() {
-			p.currentTime = time.Now().Local().In(p.loc)
-		}
+	if p.currentTime.IsZero() {
+		p.currentTime = time.Now().Local().In(p.loc)
+	}
+
+	s.expr, s.loc, s.anchor = x, p.loc, p.Anchor
+	if x.loc != nil {
+		s.loc = x.loc
+	}
+	if s.anchor.IsZero() {
+		s.anchor = p.currentTime
+	}
+	if x.reboot {
+		return s, nil
 	}
 
-	d, e := p.exprParse(expr)
+	next, e := s.next(p.currentTime)
 	if e != nil {
 		return s, e
 	}
-
-	p.calculateMinute(d, p.loc)
-	p.calculateHour(d, p.loc)
-	p.calculateDay(d, p.loc)
-	p.calculateMonth(d, p.loc)
-	p.calculateWeek(d, p.loc)
-
-	s.Next = p.currentTime
+	p.currentTime = next
+	s.Next = next
 
 	return s, nil
 }
 
-func (p *Parser) calculateMinute(d []cronDirective, loc *time.Location) {
-	m := p.currentTime.Minute()
-	if d[0].kind == every && m <= d[0].last || d[0].kind != every && m <= d[0].last ||
-		d[0].kind != one && d[1].kind != one && d[3].kind != one || d[3].kind == one && int(p.currentTime.Month()) == d[3].first {
-		p.calculate(d[0], time.Minute, m, 60, 1)
-	}
-}
-
-func (p *Parser) calculateHour(d []cronDirective, loc *time.Location) {
-	h := p.currentTime.Hour()
-	if d[1].kind == every && p.currentTime.Minute() > d[0].last || d[0].kind != every && p.currentTime.Minute() > d[0].last {
-		p.currentTime = p.reset(p.currentTime, d[0].first, "minute", p.loc)
-		p.calculate(d[1], time.Hour, h, 24, 1)
-	} else if h > d[1].last {
-		p.currentTime = p.reset(p.currentTime, d[1].first, "hour", p.loc)
-		p.currentTime = p.currentTime.AddDate(0, 0, 1)
-	}
-}
-
-func (p *Parser) calculateDay(d []cronDirective, loc *time.Location) {
-	maxDay := daySpec(p.currentTime.Year())[p.currentTime.Month()-1]
-	day := p.currentTime.Day()
+// ErrNeverMatches is returned when the cron format has no next time
+var ErrNeverMatches = errors.New("Cron format never matches a date")
 
-	var nextstep int
-	if len(d[2].stepSpan) > 0 {
-		nextstep, _ = nextStep(p.currentTime.Day(), d[2].stepSpan)
+// next returns the first activation time after from in the location
+func (s Schedule) next(from time.Time) (time.Time, error) {
+	next := s.expr.NextSince(s.anchor, from.In(s.loc))
+	if next.IsZero() {
+		return next, ErrNeverMatches
 	}
 
-	if d[3].kind != one && d[2].kind == one && p.currentTime.Hour() == d[1].first && p.currentTime.Minute() == d[0].first ||
-		d[3].kind != one && d[2].kind == everyStep && day < nextstep && p.currentTime.Hour() == d[1].first && p.currentTime.Minute() == d[0].first ||
-		d[3].kind != one && d[2].kind == stepRange && day > d[2].last && p.currentTime.Hour() == d[1].first && p.currentTime.Minute() == d[0].first {
-		p.currentTime = p.reset(p.currentTime, d[0].first, "minute", p.loc)
-		p.currentTime = p.reset(p.currentTime, d[1].first, "hour", p.loc)
-		p.calculate(d[2], time.Hour*24, day, maxDay, 1)
-	}
+	return next, nil
 }
 
-func (p *Parser) calculateMonth(d []cronDirective, loc *time.Location) {
-	m := int(p.currentTime.Month())
+// nextTime finds the first time after from which matches every field,
+// it's zero when there is none. The fields are matched on the wall clock
+// of the location of from, then the wall clock is converted to a time
+// following Vixie cron at the daylight saving time transitions:
+//
+// A wall clock skipped by the spring-forward gap runs once at the end of
+// the gap when the job has a fixed hour and minute, a job with a wildcard
+// hour or minute like `*/15 * * * *` continues at its next wall clock.
+//
+// A wall clock repeated by the fall-back runs once at its first time when
+// the job has a fixed hour and minute, a job with a wildcard hour or
+// minute runs at both times.
+func nextTime(d []cronField, from time.Time) time.Time {
+	loc := from.Location()
+	fixed := !d[fieldHour].star && !d[fieldMinute].star
+	w := wallClock(from).Add(time.Second)
+	limit := w.AddDate(yearLimit, 0, 0)
+
+	var next time.Time
+	for next.IsZero() {
+		m, ok := nextWallClock(d, w, limit)
+		if !ok {
+			return next
+		}
 
-	var nextstep int
-	var idx int
-	if len(d[3].stepSpan) > 0 {
-		nextstep, idx = nextStep(int(p.currentTime.Month()), d[3].stepSpan)
+		times, n := instants(m, loc)
+		switch {
+		case n == 0:
+			if end := gapEnd(m, loc); fixed && end.After(from) {
+				next = end
+			}
+		case fixed:
+			if times[0].After(from) {
+				next = times[0]
+			}
+		default:
+			for _, t := range times[:n] {
+				if t.After(from) {
+					next = t
+					break
+				}
+			}
+		}
+		w = m.Add(time.Second)
 	}
 
-	if d[3].kind == one && int(p.currentTime.Month()) != d[3].first && d[4].kind != one || d[3].kind == stepRange && int(p.currentTime.Month()) > d[3].last && d[4].kind != one {
-		p.currentTime = p.reset(p.currentTime, d[0].first, "minute", p.loc)
-		p.currentTime = p.reset(p.currentTime, d[1].first, "hour", p.loc)
-		p.currentTime = p.reset(p.currentTime, d[2].first, "dayMonth", p.loc)
-
-		if m >= d[3].first {
-			p.currentTime = p.currentTime.AddDate(0, 12-m+d[3].first, 0)
-		} else {
-			p.currentTime = p.currentTime.AddDate(0, d[3].first-m, 0)
+	// The current time is in the first of the repeated wall clocks,
+	// a job with a wildcard runs again in the second of them.
+	if start, end, ok := fold(from); ok && !fixed {
+		if m, ok := nextWallClock(d, start, end); ok {
+			if t, n := instants(m, loc); n == 2 && t[1].Before(next) {
+				next = t[1]
+			}
 		}
-	} else if d[3].kind == everyStep && int(p.currentTime.Month()) < nextstep && int(p.currentTime.Month()) > d[3].stepSpan[idx-1] &&
-		p.currentTime.Minute() == d[0].first && p.currentTime.Hour() == d[1].first {
-		p.currentTime = p.reset(p.currentTime, d[0].first, "minute", p.loc)
-		p.currentTime = p.reset(p.currentTime, d[1].first, "hour", p.loc)
-		p.currentTime = p.reset(p.currentTime, d[2].first, "dayMonth", p.loc)
-
-		p.currentTime = p.currentTime.AddDate(0, nextstep-m, 0)
 	}
+
+	return next
 }
 
-func (p *Parser) calculateWeek(d []cronDirective, loc *time.Location) {
-	if d[4].kind == one {
-		nextweek := weekType[d[4].first]
-		for p.currentTime.Weekday() != nextweek {
-			p.currentTime = p.currentTime.AddDate(0, 0, 1)
+// nextWallClock finds the first wall clock from w before the limit
+// which matches every field. When a field doesn't match, the wall
+// clock moves to the start of the next value of the field and the
+// fields are matched again.
+func nextWallClock(d []cronField, w, limit time.Time) (time.Time, bool) {
+	for w.Before(limit) {
+		year, month, day := w.Date()
+
+		if !d[fieldMonth].has(int(month)) {
+			w = time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
 		}
-		if p.currentTime.Weekday() == nextweek {
-			p.currentTime = p.reset(p.currentTime, d[0].first, "minute", p.loc)
-			p.currentTime = p.reset(p.currentTime, d[1].first, "hour", p.loc)
+		if !matchDay(d, w) {
+			w = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+			continue
 		}
-	} else if d[4].kind == stepRange && p.currentTime.Hour() == d[1].first && p.currentTime.Minute() == d[0].first {
-		for p.currentTime.Weekday() != weekType[d[4].first] {
-			if p.currentTime.Weekday() == weekType[d[4].last] {
-				break
+		if !d[fieldHour].has(w.Hour()) {
+			hour, ok := d[fieldHour].nextValue(w.Hour())
+			if !ok {
+				hour = 24
 			}
-			p.currentTime = p.currentTime.AddDate(0, 0, 1)
+			w = time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+			continue
 		}
-		if p.currentTime.Weekday() == weekType[d[4].first] || p.currentTime.Weekday() == weekType[d[4].last] {
-			p.currentTime = p.reset(p.currentTime, d[0].first, "minute", p.loc)
-			p.currentTime = p.reset(p.currentTime, d[1].first, "hour", p.loc)
+		if !d[fieldMinute].has(w.Minute()) {
+			minute, ok := d[fieldMinute].nextValue(w.Minute())
+			if !ok {
+				minute = 60
+			}
+			w = time.Date(year, month, day, w.Hour(), minute, 0, 0, time.UTC)
+			continue
 		}
-	} else if d[4].kind == everyStep && p.currentTime.Minute() == d[0].first && p.currentTime.Hour() == d[1].first {
-		p.currentTime = p.reset(p.currentTime, d[0].first, "minute", p.loc)
-		p.currentTime = p.reset(p.currentTime, d[1].first, "hour", p.loc)
-
-		var nextstep int
-		var idx int
-		if len(d[4].stepSpan) > 0 {
-			nextstep, idx = nextStep(p.currentTime.Day(), d[4].stepSpan)
+		if !d[fieldSecond].has(w.Second()) {
+			second, ok := d[fieldSecond].nextValue(w.Second())
+			if !ok {
+				second = 60
+			}
+			w = time.Date(year, month, day, w.Hour(), w.Minute(), second, 0, time.UTC)
+			continue
 		}
 
-		if p.currentTime.Day() != d[4].stepSpan[idx-1] {
-			p.currentTime = p.currentTime.AddDate(0, 0, nextstep-p.currentTime.Day())
-		}
+		return w, true
 	}
 
+	return time.Time{}, false
 }
 
-func (p *Parser) exprParse(expr []string) (directive []cronDirective, e error) {
-	lastTimeCollection := []int{59, 23, daySpec(p.currentTime.Year())[11], 12, 6}
-	for i, val := range expr {
-		sval := strings.ToLower(val)
-		dtmp := cronDirective{
-			expr: sval,
-			kind: 0,
-		}
-		pattern := regexTimeCollection[i]
-		lastTime := lastTimeCollection[i]
-
-		// `*`
-		if makeLayoutRegexp(layoutWildcard, pattern).MatchString(sval) {
-			dtmp.kind = every
-			dtmp.step = 1
-			if i < 2 {
-				dtmp.first = 0
-			} else {
-				dtmp.first = 1
-			}
-			dtmp.last = lastTime
+// nextInterval returns the first interval of d after from,
+// the intervals start at the anchor
+func nextInterval(anchor, from time.Time, d time.Duration) time.Time {
+	if from.Before(anchor) {
+		return anchor.In(from.Location())
+	}
 
-			directive = append(directive, dtmp)
-			continue
+	n := from.Sub(anchor)/d + 1
+
+	return anchor.Add(n * d).In(from.Location())
+}
+
+// IsReboot reports if the cron format is @reboot, the job runs
+// once every time the scheduler starts
+func IsReboot(expr []string) bool {
+	_, expr, _ = SplitZone(expr)
+	return len(expr) == 1 && strings.ToLower(expr[0]) == "@reboot"
+}
+
+// matchDay follows Vixie cron, when the day of month and the weekday
+// are both restricted, a day that matches one of them is used.
+// Otherwise the day should match both of them.
+func matchDay(d []cronField, t time.Time) bool {
+	dayMonth := matchDayMonth(&d[fieldDayMonth], t)
+	weekday := matchWeekday(&d[fieldWeekday], t)
+
+	if d[fieldDayMonth].star || d[fieldWeekday].star {
+		return dayMonth && weekday
+	}
+
+	return dayMonth || weekday
+}
+
+func matchDayMonth(f *cronField, t time.Time) bool {
+	year, month, day := t.Date()
+	if f.has(day) ||
+		f.lastDay && day == lastDay(year, month) ||
+		f.lastWeekday && day == lastWeekday(year, month, t.Location()) {
+		return true
+	}
+	// The nearest weekday is at most 2 days from the day
+	for n := day - 2; f.nearest != 0 && n <= day+2; n++ {
+		if n > 0 && f.nearest&(1<<uint(n)) != 0 && day == nearestWeekday(year, month, n, t.Location()) {
+			return true
 		}
-		// `1`
-		if makeLayoutRegexp(layoutValue, pattern).MatchString(sval) {
-			dtmp.kind = one
+	}
 
-			d, e := strconv.Atoi(sval)
-			if e != nil {
-				dtmp.step = 0
-			}
-			dtmp.step = d
-			dtmp.first = d
-			dtmp.last = d
+	return false
+}
 
-			directive = append(directive, dtmp)
-			continue
+func matchWeekday(f *cronField, t time.Time) bool {
+	year, month, day := t.Date()
+	weekday := uint(t.Weekday())
+
+	return f.has(int(weekday)) ||
+		f.lastOf&(1<<weekday) != 0 && day+7 > lastDay(year, month) ||
+		f.nthOf[weekday]&(1<<uint((day-1)/7+1)) != 0
+}
+
+// exprParse compiles every field of a validated cron format
+// to the set of its allowed values
+func (p *Parser) exprParse(expr []string) (directive []cronField, e error) {
+	if len(expr) == 5 {
+		expr = append([]string{"0"}, expr...)
+	}
+
+	for i, val := range expr {
+		f := cronField{
+			expr: strings.ToLower(val),
+			star: strings.HasPrefix(val, "*"),
 		}
-		// `1-2`
-		pairs := makeLayoutRegexp(layoutRange, pattern).FindStringSubmatchIndex(sval)
-		if len(pairs) > 0 {
-			dtmp.kind = stepRange
-			first, _ := strconv.Atoi(sval[pairs[2]:pairs[3]])
-			dtmp.first = first
-
-			second, _ := strconv.Atoi(sval[pairs[4]:pairs[5]])
-			dtmp.last = second
-			dtmp.step = 1
-
-			directive = append(directive, dtmp)
-			continue
+		switch i {
+		case fieldMonth:
+			f.expr = replaceNames(f.expr, monthNames)
+		case fieldWeekday:
+			f.expr = replaceNames(f.expr, weekdayNames)
 		}
 
-		// `*/2`
-		pairs = makeLayoutRegexp(layoutWildcardAndInterval, pattern).FindStringSubmatchIndex(sval)
-		if len(pairs) > 0 {
-			var lspan int
-			j := 0
-			step := 0
-
-			dtmp.kind = everyStep
-			d, _ := strconv.Atoi(sval[pairs[2]:pairs[3]])
-
-			switch exprType[i] {
-			case "minute":
-				lspan = int(math.Round(float64(60 / d)))
-			case "hour":
-				lspan = int(math.Round(float64(24 / d)))
-			case "dayMonth":
-				maxDay := daySpec(p.currentTime.Year())[p.currentTime.Month()-1]
-				lspan = int(math.Round(float64(maxDay / d)))
-			case "month":
-				lspan = int(math.Round(float64(12 / d)))
-			case "weekday":
-				maxDay := daySpec(p.currentTime.Year())[p.currentTime.Month()-1]
-				lspan = int(math.Round(float64(maxDay / d)))
-				step = 1
+		for _, entry := range entryFinder.FindAllString(f.expr, -1) {
+			if parseModifier(&f, i, entry) {
+				continue
 			}
 
-			for j <= lspan {
-				dtmp.stepSpan = append(dtmp.stepSpan, step)
-				j++
-				step += d
+			first, last, step, e := parseEntry(entry, regexTimeCollection[i], fieldMin[i], fieldMax[i])
+			if e != nil {
+				return directive, fmt.Errorf("Cron format %s %v", exprType[i], e)
 			}
 
-			dtmp.last = dtmp.stepSpan[len(dtmp.stepSpan)-1]
-			directive = append(directive, dtmp)
-			continue
+			for v := first; v <= last; v += step {
+				f.bits |= 1 << uint(v)
+			}
 		}
-	}
 
-	return directive, e
-}
+		// 7 is Sunday, like 0
+		if i == fieldWeekday && f.has(7) {
+			f.bits = f.bits&^(1<<7) | 1
+		}
 
-func (p *Parser) reset(t time.Time, first int, kind string, loc *time.Location) time.Time {
-	switch kind {
-	case "minute":
-		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), first, 0, 0, loc)
-	case "hour":
-		return time.Date(t.Year(), t.Month(), t.Day(), first, t.Minute(), 0, 0, loc)
-	case "dayMonth":
-		return time.Date(t.Year(), t.Month(), first, t.Hour(), t.Minute(), 0, 0, loc)
-	case "month":
-		return time.Date(t.Year(), time.Month(first), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+		directive = append(directive, f)
 	}
 
-	return t
+	return directive, nil
 }
 
-func (p *Parser) calculate(d cronDirective, td time.Duration, tnow, max, seconds int) bool {
-	var next bool
-	var diff int
-	switch d.kind {
-	case one:
-		if tnow < d.step {
-			diff = d.step - tnow
-		} else {
-			diff = max - tnow + d.step
+// parseModifier adds the L, W or # modifier of an entry of the day of
+// month or the weekday to the field. It returns false for other entries.
+func parseModifier(f *cronField, i int, entry string) bool {
+	pattern := regexTimeCollection[i]
+	switch i {
+	case fieldDayMonth:
+		if makeLayoutRegexp(layoutLastDayOfMonth, pattern).MatchString(entry) {
+			f.lastDay = true
+			return true
+		}
+		if makeLayoutRegexp(layoutLastWeekdayOfMonth, pattern).MatchString(entry) {
+			f.lastWeekday = true
+			return true
+		}
+		if m := makeLayoutRegexp(layoutNearestWeekday, pattern).FindStringSubmatch(entry); m != nil {
+			day, _ := strconv.Atoi(m[1])
+			f.nearest |= 1 << uint(day)
+			return true
 		}
-	case stepRange:
-		if tnow < d.first {
-			diff = d.first - tnow
-		} else if tnow < d.last {
-			diff = 1
-		} else if tnow >= d.last {
-			diff = (max - tnow) + d.first
+	case fieldWeekday:
+		if m := makeLayoutRegexp(layoutLastDowOfMonth, pattern).FindStringSubmatch(entry); m != nil {
+			weekday, _ := strconv.Atoi(m[1])
+			f.lastOf |= 1 << uint(weekday%7)
+			return true
+		}
+		if m := makeLayoutRegexp(layoutDowOfSpecificWeek, pattern).FindStringSubmatch(entry); m != nil {
+			weekday, _ := strconv.Atoi(m[1])
+			nth, _ := strconv.Atoi(m[2])
+			f.nthOf[weekday%7] |= 1 << uint(nth)
+			return true
 		}
-	case everyStep:
-		step, _ := nextStep(tnow, d.stepSpan)
-		diff = step - tnow
-	case every:
-		diff = d.step * seconds
 	}
 
-	p.currentTime = p.currentTime.Add(td * time.Duration(diff))
+	return false
+}
 
-	return next
+// parseEntry returns the range and the step of an entry of a comma list:
+// `*`, `1`, `1-5`, `*/2`, `1/2` or `1-5/2`.
+func parseEntry(entry, pattern string, min, max int) (first, last, step int, e error) {
+	atoi := func(s string) int {
+		v, _ := strconv.Atoi(s)
+		return v
+	}
+
+	first, last, step = min, max, 1
+	if m := makeLayoutRegexp(layoutWildcard, pattern).FindStringSubmatch(entry); m != nil {
+		// every value
+	} else if m := makeLayoutRegexp(layoutValue, pattern).FindStringSubmatch(entry); m != nil {
+		first, last = atoi(m[1]), atoi(m[1])
+	} else if m := makeLayoutRegexp(layoutRange, pattern).FindStringSubmatch(entry); m != nil {
+		first, last = atoi(m[1]), atoi(m[2])
+	} else if m := makeLayoutRegexp(layoutWildcardAndInterval, pattern).FindStringSubmatch(entry); m != nil {
+		step = atoi(m[1])
+	} else if m := makeLayoutRegexp(layoutValueAndInterval, pattern).FindStringSubmatch(entry); m != nil {
+		first, step = atoi(m[1]), atoi(m[2])
+	} else if m := makeLayoutRegexp(layoutRangeAndInterval, pattern).FindStringSubmatch(entry); m != nil {
+		first, last, step = atoi(m[1]), atoi(m[2]), atoi(m[3])
+	} else {
+		return 0, 0, 0, fmt.Errorf("%q is incorrect", entry)
+	}
+
+	if first < min || last > max {
+		return 0, 0, 0, fmt.Errorf("%q is out of range %d-%d", entry, min, max)
+	}
+	if first > last {
+		return 0, 0, 0, fmt.Errorf("%q is a reversed range", entry)
+	}
+	if step < 1 {
+		return 0, 0, 0, fmt.Errorf("%q has a zero step", entry)
+	}
+
+	return first, last, step, nil
 }
@@ -0,0 +1,203 @@
+package cronparser
+
+import (
+	"time"
+)
+
+// NextN returns the next n activation times after from,
+// it's shorter when the cron format stops matching
+func (s Schedule) NextN(from time.Time, n int) []time.Time {
+	var times []time.Time
+	if !s.scheduled() {
+		return times
+	}
+
+	for len(times) < n {
+		next, e := s.next(from)
+		if e != nil {
+			break
+		}
+		times = append(times, next)
+		from = next
+	}
+
+	return times
+}
+
+// Between returns every activation time after from until to, to is included.
+// A wide range of a frequent cron format returns many times.
+func (s Schedule) Between(from, to time.Time) []time.Time {
+	var times []time.Time
+	if !s.scheduled() {
+		return times
+	}
+
+	for {
+		next, e := s.next(from)
+		if e != nil || next.After(to) {
+			break
+		}
+		times = append(times, next)
+		from = next
+	}
+
+	return times
+}
+
+// Prev returns the last activation time before from,
+// it's zero when there is none
+func (s Schedule) Prev(from time.Time) time.Time {
+	if !s.scheduled() {
+		return time.Time{}
+	}
+	if s.expr.every > 0 {
+		return s.prevInterval(from)
+	}
+
+	return s.prev(from)
+}
+
+// Matches reports if the wall clock of t in the location of the schedule
+// matches every field, at the precision of a second. The end of the
+// spring-forward gap is not matched for a wall clock skipped by the gap.
+func (s Schedule) Matches(t time.Time) bool {
+	if !s.scheduled() {
+		return false
+	}
+	if s.expr.every > 0 {
+		elapsed := t.Sub(s.anchor)
+		return elapsed >= 0 && elapsed%s.expr.every < time.Second
+	}
+
+	d := s.expr.fields
+	w := wallClock(t.In(s.loc))
+
+	return d[fieldMonth].has(int(w.Month())) &&
+		matchDay(d, w) &&
+		d[fieldHour].has(w.Hour()) &&
+		d[fieldMinute].has(w.Minute()) &&
+		d[fieldSecond].has(w.Second())
+}
+
+// scheduled reports if the schedule has activation times,
+// @reboot and a zero Schedule have none
+func (s Schedule) scheduled() bool {
+	return s.expr != nil && !s.expr.reboot
+}
+
+// prev is the reverse of next, it follows the same conventions
+// at the daylight saving time transitions.
+func (s Schedule) prev(from time.Time) time.Time {
+	d := s.expr.fields
+	from = from.In(s.loc)
+	fixed := !d[fieldHour].star && !d[fieldMinute].star
+	w := wallClock(from)
+	limit := w.AddDate(-yearLimit, 0, 0)
+
+	// The current time is in the second of the repeated wall clocks, a job
+	// with a wildcard ran in the second of them before the current time,
+	// otherwise in the first of them whatever their wall clock.
+	if start, end, ok := unfold(from); ok {
+		if !fixed {
+			for m, ok := prevWallClock(d, w, start); ok; m, ok = prevWallClock(d, m.Add(-time.Second), start) {
+				if t, n := instants(m, s.loc); n == 2 && t[1].Before(from) {
+					return t[1]
+				}
+			}
+		}
+		if m, ok := prevWallClock(d, end.Add(-time.Second), start); ok {
+			if t, n := instants(m, s.loc); n == 2 {
+				return t[0]
+			}
+		}
+		w = start.Add(-time.Second)
+	}
+
+	var prev time.Time
+	for prev.IsZero() {
+		m, ok := prevWallClock(d, w, limit)
+		if !ok {
+			break
+		}
+
+		times, n := instants(m, s.loc)
+		switch {
+		case n == 0:
+			if end := gapEnd(m, s.loc); fixed && end.Before(from) {
+				prev = end
+			}
+		case fixed:
+			if times[0].Before(from) {
+				prev = times[0]
+			}
+		default:
+			for i := n - 1; i >= 0; i-- {
+				if times[i].Before(from) {
+					prev = times[i]
+					break
+				}
+			}
+		}
+		w = m.Add(-time.Second)
+	}
+
+	return prev
+}
+
+// prevWallClock finds the last wall clock from w not before the limit
+// which matches every field. When a field doesn't match, the wall
+// clock moves to the end of the previous value of the field.
+func prevWallClock(d []cronField, w, limit time.Time) (time.Time, bool) {
+	for !w.Before(limit) {
+		year, month, day := w.Date()
+
+		if !d[fieldMonth].has(int(month)) {
+			w = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
+			continue
+		}
+		if !matchDay(d, w) {
+			w = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Add(-time.Second)
+			continue
+		}
+		if !d[fieldHour].has(w.Hour()) {
+			w = time.Date(year, month, day, w.Hour(), 0, 0, 0, time.UTC).Add(-time.Second)
+			continue
+		}
+		if !d[fieldMinute].has(w.Minute()) {
+			w = time.Date(year, month, day, w.Hour(), w.Minute(), 0, 0, time.UTC).Add(-time.Second)
+			continue
+		}
+		if !d[fieldSecond].has(w.Second()) {
+			w = w.Add(-time.Second)
+			continue
+		}
+
+		return w, true
+	}
+
+	return time.Time{}, false
+}
+
+// prevInterval returns the last interval of @every before from
+func (s Schedule) prevInterval(from time.Time) time.Time {
+	if !from.After(s.anchor) {
+		return time.Time{}
+	}
+
+	n := (from.Sub(s.anchor) - 1) / s.expr.every
+
+	return s.anchor.Add(n * s.expr.every).In(s.loc)
+}
+
+// unfold returns the repeated wall clocks [start, end) when t is
+// in the second of them, after the fall-back transition.
+func unfold(t time.Time) (start, end time.Time, ok bool) {
+	times, n := instants(wallClock(t), t.Location())
+	if n != 2 || !times[1].Equal(t.Truncate(time.Second)) {
+		return start, end, false
+	}
+
+	start = wallClock(transition(times[0], times[1], t.Location()))
+
+	return start, start.Add(times[1].Sub(times[0])), true
+}
@@ -8,13 +8,8 @@ import (
 )
 
 var (
-	one                       = 1
-	everyStep                 = 2
-	stepRange                 = 3
-	every                     = 4
-	exprType                  = []string{"minute", "hour", "dayMonth", "month", "weekday"}
-	regexTimeCollection       = []string{minuteValue, hourValue, dayMonthValue, monthValue, weekdayValue}
-	weekType                  = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+	exprType                  = []string{"second", "minute", "hour", "day month", "month", "weekday"}
+	regexTimeCollection       = []string{minuteValue, minuteValue, hourValue, dayMonthValue, monthValue, weekdayValue}
 	layoutWildcard            = `^[*]$`
 	layoutValue               = `^(%value%)$`
 	layoutRange               = `^(%value%)-(%value%)$`
@@ -22,12 +17,67 @@ var (
 	layoutValueAndInterval    = `^(%value%)/(\d+)$`
 	layoutRangeAndInterval    = `^(%value%)-(%value%)/(\d+)$`
 	layoutDowOfSpecificWeek   = `^(%value%)#([1-5])$`
+	layoutLastDowOfMonth      = `^(%value%)l$`
+	layoutNearestWeekday      = `^(%value%)w$`
+	layoutLastDayOfMonth      = `^l$`
+	layoutLastWeekdayOfMonth  = `^lw$`
 	fieldFinder               = regexp.MustCompile(`\S+`)
 	entryFinder               = regexp.MustCompile(`[^,]+`)
+	nameFinder                = regexp.MustCompile(`[a-zA-Z]{3}`)
 	layoutRegexp              = make(map[string]*regexp.Regexp)
 	layoutRegexpLock          sync.Mutex
 )
 
+// The prefixes of the time zone of a cron format, like `CRON_TZ=America/New_York`
+var zonePrefixes = []string{"CRON_TZ=", "TZ="}
+
+// SplitZone returns the time zone of a cron format starting with
+// `CRON_TZ=` or `TZ=` and its other fields, ok is false when the
+// cron format has no time zone
+func SplitZone(expr []string) (zone string, fields []string, ok bool) {
+	if len(expr) > 0 {
+		for _, prefix := range zonePrefixes {
+			if strings.HasPrefix(strings.ToUpper(expr[0]), prefix) {
+				return expr[0][len(prefix):], expr[1:], true
+			}
+		}
+	}
+
+	return "", expr, false
+}
+
+// WithZone returns the cron format in the time zone, a cron format
+// starting with `CRON_TZ=` or `TZ=` keeps its own time zone. The cron
+// format is kept as it is when the time zone is empty.
+func WithZone(expr []string, zone string) []string {
+	if _, _, ok := SplitZone(expr); ok || zone == "" {
+		return expr
+	}
+
+	return append([]string{"CRON_TZ=" + zone}, expr...)
+}
+
+// The cron formats of the macros, @every and @reboot have no cron format
+var macros = map[string][]string{
+	"@yearly":   {"0", "0", "1", "1", "*"},
+	"@annually": {"0", "0", "1", "1", "*"},
+	"@monthly":  {"0", "0", "1", "*", "*"},
+	"@weekly":   {"0", "0", "*", "*", "0"},
+	"@daily":    {"0", "0", "*", "*", "*"},
+	"@midnight": {"0", "0", "*", "*", "*"},
+	"@hourly":   {"0", "*", "*", "*", "*"},
+}
+
+// The names of the months and the weekdays, 7 is Sunday too
+var monthNames = map[string]string{
+	"jan": "1", "feb": "2", "mar": "3", "apr": "4", "may": "5", "jun": "6",
+	"jul": "7", "aug": "8", "sep": "9", "oct": "10", "nov": "11", "dec": "12",
+}
+
+var weekdayNames = map[string]string{
+	"sun": "0", "mon": "1", "tue": "2", "wed": "3", "thu": "4", "fri": "5", "sat": "6",
+}
+
 var normalDaySpec = []int{
 	31, // January
 	28, // February
@@ -58,18 +108,15 @@ var leapDaySpec = []int{
 	31, // December
 }
 
-func nextStep(val int, nums []int) (next int, index int) {
-	for i := 0; i < len(nums); i++ {
-		if i != len(nums)-1 {
-			if val > nums[i] && val < nums[i+1] || val == nums[i] {
-				return nums[i+1], i + 1
-			}
-		} else {
-			return nums[len(nums)-1], len(nums) - 1
+// replaceNames replaces the names of a field with their numbers,
+// the names are case-insensitive. An unknown name is kept as it is.
+func replaceNames(field string, names map[string]string) string {
+	return nameFinder.ReplaceAllStringFunc(field, func(name string) string {
+		if v, ok := names[strings.ToLower(name)]; ok {
+			return v
 		}
-	}
-
-	return nums[1], 1
+		return name
+	})
 }
 
 func makeLayoutRegexp(layout, value string) *regexp.Regexp {
@@ -93,3 +140,46 @@ func daySpec(year int) []int {
 
 	return normalDaySpec
 }
+
+// lastDay returns the last day of the month
+func lastDay(year int, month time.Month) int {
+	return daySpec(year)[month-1]
+}
+
+// nearestWeekday returns the weekday nearest to the day of the month
+// for the W modifier, it doesn't move to another month. It returns 0
+// when the month doesn't have the day.
+func nearestWeekday(year int, month time.Month, day int, loc *time.Location) int {
+	last := lastDay(year, month)
+	if day > last {
+		return 0
+	}
+
+	switch time.Date(year, month, day, 0, 0, 0, 0, loc).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+
+	return day
+}
+
+// lastWeekday returns the last weekday of the month for the LW modifier
+func lastWeekday(year int, month time.Month, loc *time.Location) int {
+	day := lastDay(year, month)
+	switch time.Date(year, month, day, 0, 0, 0, 0, loc).Weekday() {
+	case time.Saturday:
+		return day - 1
+	case time.Sunday:
+		return day - 2
+	}
+
+	return day
+}
@@ -1,97 +1,469 @@
 package cronparser
 
 import (
-	"errors"
-	"regexp"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Regex to validate cron format
+// Regex of the values of the fields, see regexTimeCollection
 const (
-	minute        = `^(\*|[1-5]?[0-9](-[1-5]?[0-9])?)(\/[1-5][0-9]*)?(,(\*|[1-5]?[0-9](-[1-5]?[0-9])?)(\/[1-9][0-9]*)?)*$`
 	minuteValue   = `0?[0-9]|[1-5][0-9]`
-	hour          = `^(\*|(1?[0-9]|2[0-3])(-(1?[0-9]|2[0-3]))?)(\/(1?[0-9]|2[0-3])(-(1?[0-9]|2[0-3]))?)?(,(\*|(1?[0-9]|2[0-3])(-(1?[0-9]|2[0-3]))?)(\/[1-9][0-9]*)?)*$`
 	hourValue     = `0?[0-9]|1[0-9]|2[0-3]`
-	dayMonth      = `^(\*|([1-9]|[1-2][0-9]?|3[0-1])(-([1-9]|[1-2][0-9]?|3[0-1]))?)(\/(1?[0-9]|2[0-9]|3[0-1]))?(,(\*|([1-9]|[1-2][0-9]?|3[0-1])(-([1-9]|[1-2][0-9]?|3[0-1]))?)?)*$`
 	dayMonthValue = `0?[1-9]|[12][0-9]|3[01]`
-	month         = `^(\*|([1-9]|1[0-2]?)(-([1-9]|1[0-2]?))?)(\/[1-9][0-9]*)?(,(\*|([1-9]|1[0-2]?)(-([1-9]|1[0-2]?))?)(\/[1-9][0-9]*)?)*$`
 	monthValue    = `0?[1-9]|1[012]`
-	weekday       = `^(?:MON|TUE|WED|THU|FRI|SAT|SUN)|(\*|[0-6](-[0-6])?)(\/[1-9][0-9]*)?(,(\*|[0-6](-[0-6])?)(\/[1-9][0-9]*)?)*$`
-	weekdayValue  = `0?[0-7]|MON|TUE|WED|THU|FRI|SAT|SUN`
+	weekdayValue  = `0?[0-7]`
 )
 
-// validate cron format
+// ValidationError describes the first incorrect token of a cron format
+type ValidationError struct {
+	Field      string // The field of the token like `minute`, it's empty for the whole cron format
+	Token      string // The incorrect token
+	Offset     int    // The offset of the token in the cron format joined by spaces
+	Reason     string // Why the token is incorrect, like `is out of range`
+	Expected   string // The expected values, like `0-59`
+	Suggestion string // The fixed cron format, it's empty when there is none
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("Cron format")
+	if e.Field != "" {
+		b.WriteString(" " + e.Field)
+	}
+	fmt.Fprintf(&b, " %q at offset %d %s", e.Token, e.Offset, e.Reason)
+	if e.Expected != "" {
+		fmt.Fprintf(&b, ", expected %s", e.Expected)
+	}
+	if e.Suggestion != "" {
+		fmt.Fprintf(&b, ", did you mean %q?", e.Suggestion)
+	}
+
+	return b.String()
+}
+
+// The expected values of the fields
+var fieldExpected = []string{
+	"0-59",
+	"0-59",
+	"0-23",
+	"1-31, L, LW or 1W-31W",
+	"1-12 or JAN-DEC",
+	"0-7 or SUN-SAT, with L or #1-#5",
+}
+
+// Validate checks every token of the cron format, the error is a *ValidationError.
+// The warnings describe a valid cron format which never runs or rarely runs,
+// like `0 0 30 2 *`.
+func Validate(expr []string) (warnings []string, e error) {
+	if e := validate(expr); e != nil {
+		return nil, e
+	}
+	_, expr, _ = SplitZone(expr)
+	if strings.HasPrefix(expr[0], "@") {
+		return nil, nil
+	}
+
+	var p Parser
+	d, e := p.exprParse(expr)
+	if e != nil {
+		return nil, e
+	}
+
+	return dateWarnings(d), nil
+}
+
+// validate cron format, a cron format with 6 fields starts with the seconds.
+// A cron format can start with its time zone like `CRON_TZ=America/New_York`.
+// It returns the first incorrect token as a *ValidationError.
 func validate(expr []string) error {
+	if zone, fields, ok := SplitZone(expr); ok {
+		return validateZone(expr[0], zone, fields)
+	}
 	if len(expr) == 0 {
-		return errors.New("Cron format cannot be empty string")
+		return &ValidationError{Reason: "is empty", Expected: "5 or 6 fields or a macro"}
+	}
+	if strings.HasPrefix(expr[0], "@") {
+		return validateMacro(expr)
 	}
-	if len(expr) > 5 {
-		return errors.New("Cron format is incorrect")
+	if len(expr) < 5 || len(expr) > 6 {
+		e := &ValidationError{
+			Token:    strings.Join(expr, " "),
+			Reason:   fmt.Sprintf("has %d fields", len(expr)),
+			Expected: "5 or 6 fields",
+		}
+		if len(expr) < 5 {
+			fixed := append(append([]string{}, expr...), "*", "*", "*", "*")
+			e.Suggestion = strings.Join(fixed[:5], " ")
+		}
+		return e
 	}
-	if validateMinute(expr[0]) == false {
-		return errors.New("Cron format minute is incorrect")
+
+	first, offset := fieldSecond, 0
+	if len(expr) == 5 {
+		first = fieldMinute
 	}
-	if validateHour(expr[1]) == false {
-		return errors.New("Cron format hour is incorrect")
+	for i, token := range expr {
+		if e := validateField(first+i, token); e != nil {
+			e.Offset += offset
+			if e.Suggestion != "" {
+				fixed := append([]string{}, expr...)
+				fixed[i] = e.Suggestion
+				e.Suggestion = strings.Join(fixed, " ")
+			}
+			return e
+		}
+		offset += len(token) + 1
+	}
+
+	return nil
+}
+
+// validateZone validates the time zone of a cron format and its fields,
+// the offsets of the fields start after the time zone.
+func validateZone(prefix, zone string, fields []string) error {
+	if _, e := time.LoadLocation(zone); e != nil || zone == "" {
+		return &ValidationError{
+			Field:    "timezone",
+			Token:    zone,
+			Offset:   len(prefix) - len(zone),
+			Reason:   "is unknown",
+			Expected: "a time zone like Asia/Jakarta",
+		}
 	}
-	if validateDayMonth(expr[2]) == false {
-		return errors.New("Cron format day month is incorrect")
+	if _, _, ok := SplitZone(fields); ok {
+		return &ValidationError{Field: "timezone", Token: fields[0], Offset: len(prefix) + 1, Reason: "is repeated"}
 	}
-	if validateMonth(expr[3]) == false {
-		return errors.New("Cron format month is incorrect")
+
+	e := validate(fields)
+	if invalid, ok := e.(*ValidationError); ok {
+		invalid.Offset += len(prefix) + 1
+		if invalid.Suggestion != "" {
+			invalid.Suggestion = prefix + " " + invalid.Suggestion
+		}
 	}
-	if validateWeekday(expr[4]) == false {
-		return errors.New("Cron format weekday is incorrect")
+
+	return e
+}
+
+// validateField validates every entry of the comma list of a field,
+// the suggestion of the error is the fixed field.
+func validateField(i int, token string) *ValidationError {
+	entries := strings.Split(token, ",")
+	offset := 0
+	for j, entry := range entries {
+		if e := validateEntry(i, entry); e != nil {
+			e.Field = exprType[i]
+			e.Offset += offset
+			if e.Suggestion != "" {
+				fixed := append([]string{}, entries...)
+				fixed[j] = e.Suggestion
+				e.Suggestion = strings.Join(fixed, ",")
+			}
+			return e
+		}
+		offset += len(entry) + 1
 	}
 
 	return nil
 }
 
-func validateMinute(s string) bool {
-	e := regexp.MustCompile(minute)
-	match := e.FindAllString(s, -1)
-	if len(match) == 0 {
-		return false
+// validateEntry validates an entry of a comma list: `*`, `1`, `1-5`,
+// `*/2`, `1/2`, `1-5/2` or a modifier. The suggestion of the error
+// is the fixed entry.
+func validateEntry(i int, entry string) *ValidationError {
+	fail := func(reason, suggestion string) *ValidationError {
+		return &ValidationError{Token: entry, Reason: reason, Expected: fieldExpected[i], Suggestion: suggestion}
+	}
+
+	lower := strings.ToLower(entry)
+	if lower == "" {
+		return fail("is empty", "")
+	}
+	if e, ok := validateModifier(i, entry, lower); ok {
+		return e
 	}
 
-	return true
+	base, step := lower, ""
+	if k := strings.Index(lower, "/"); k >= 0 {
+		base, step = lower[:k], lower[k:]
+		if n, ok := number(step[1:]); !ok || n < 1 || n > fieldMax[i] {
+			e := fail("has an incorrect step", "")
+			e.Expected = fmt.Sprintf("a step of 1-%d", fieldMax[i])
+			if n > fieldMax[i] {
+				e.Suggestion = fmt.Sprintf("%s/%d", base, fieldMax[i])
+			} else if ok {
+				e.Suggestion = base
+			}
+			return e
+		}
+	}
+	if base == "*" {
+		return nil
+	}
+
+	bounds := strings.Split(base, "-")
+	if len(bounds) > 2 {
+		return fail("is incorrect", "")
+	}
+	values := make([]int, len(bounds))
+	for k, b := range bounds {
+		v, ok := fieldValue(i, b)
+		if !ok {
+			name := nameSuggestion(i, b)
+			if name == "" {
+				return fail("is incorrect", "")
+			}
+			bounds[k] = name
+			return fail("is incorrect", strings.Join(bounds, "-")+step)
+		}
+		if v < fieldMin[i] || v > fieldMax[i] {
+			bounds[k] = strconv.Itoa(clamp(v, fieldMin[i], fieldMax[i]))
+			return fail("is out of range", strings.Join(bounds, "-")+step)
+		}
+		values[k] = v
+	}
+
+	if len(values) == 2 && values[0] > values[1] {
+		start, max := values[0], fieldMax[i]
+		if i == fieldWeekday {
+			// 7 is Sunday, like 0
+			start, max = start%7, 6
+		}
+		if start <= values[1] {
+			return fail("is a reversed range", fmt.Sprintf("%d-%d%s", start, values[1], step))
+		}
+		return fail("is a reversed range", fmt.Sprintf("%d-%d,%d-%d%s", start, max, fieldMin[i], values[1], step))
+	}
+
+	return nil
 }
 
-func validateHour(s string) bool {
-	e := regexp.MustCompile(hour)
-	match := e.FindAllString(s, -1)
-	if len(match) == 0 {
-		return false
+// validateModifier validates the L, W or # modifier of the day of month
+// or the weekday. It returns false when the entry is not a modifier.
+func validateModifier(i int, entry, lower string) (*ValidationError, bool) {
+	outOfRange := func(expected, suggestion string) (*ValidationError, bool) {
+		return &ValidationError{Token: entry, Reason: "is out of range", Expected: expected, Suggestion: suggestion}, true
 	}
 
-	return true
+	switch {
+	case i == fieldDayMonth && (lower == "l" || lower == "lw"):
+		return nil, true
+	case i == fieldDayMonth && strings.HasSuffix(lower, "w"):
+		day, ok := number(strings.TrimSuffix(lower, "w"))
+		if !ok {
+			return nil, false
+		}
+		if day < 1 || day > 31 {
+			return outOfRange("1W-31W", fmt.Sprintf("%dW", clamp(day, 1, 31)))
+		}
+		return nil, true
+	case i == fieldWeekday && strings.HasSuffix(lower, "l"):
+		weekday, ok := fieldValue(i, strings.TrimSuffix(lower, "l"))
+		if !ok {
+			return nil, false
+		}
+		if weekday > 7 {
+			return outOfRange("0L-7L", "7L")
+		}
+		return nil, true
+	case i == fieldWeekday && strings.Contains(lower, "#"):
+		k := strings.Index(lower, "#")
+		weekday, ok := fieldValue(i, lower[:k])
+		if !ok {
+			return nil, false
+		}
+		if weekday > 7 {
+			return outOfRange("0-7 or SUN-SAT", fmt.Sprintf("7%s", lower[k:]))
+		}
+		if nth, ok := number(lower[k+1:]); !ok || nth < 1 || nth > 5 {
+			return outOfRange("#1-#5", fmt.Sprintf("%s#%d", lower[:k], clamp(nth, 1, 5)))
+		}
+		return nil, true
+	}
+
+	return nil, false
 }
 
-func validateDayMonth(s string) bool {
-	e := regexp.MustCompile(dayMonth)
-	match := e.FindAllString(s, -1)
-	if len(match) == 0 {
-		return false
+// validateMacro validates a macro like `@daily`, `@reboot` or `@every 1h30m`
+func validateMacro(expr []string) error {
+	macro := strings.ToLower(expr[0])
+	fail := func(token, reason, expected, suggestion string) error {
+		e := &ValidationError{Field: "macro", Token: token, Reason: reason, Expected: expected, Suggestion: suggestion}
+		if token != expr[0] {
+			e.Offset = len(expr[0]) + 1
+		}
+		return e
+	}
+
+	if macro == "@every" {
+		if len(expr) != 2 {
+			return fail(strings.Join(expr, " "), "needs a duration", "a duration like 1h30m", "@every 1h")
+		}
+		d, e := time.ParseDuration(expr[1])
+		if e != nil {
+			return fail(expr[1], "is incorrect", "a duration like 1h30m", "")
+		}
+		if d < time.Second {
+			return fail(expr[1], "is too short", "at least 1s", "@every 1s")
+		}
+		return nil
 	}
 
-	return true
+	if _, ok := macros[macro]; !ok && macro != "@reboot" {
+		names := []string{"@reboot", "@every"}
+		for name := range macros {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		suggestion := closest(macro, names, 2)
+		if suggestion == "@every" {
+			suggestion += " 1h"
+		}
+		return fail(expr[0], "is unknown", strings.Join(names, ", "), suggestion)
+	}
+	if len(expr) != 1 {
+		return fail(expr[1], "is not expected after the macro", "no fields", expr[0])
+	}
+
+	return nil
 }
 
-func validateMonth(s string) bool {
-	e := regexp.MustCompile(month)
-	match := e.FindAllString(s, -1)
-	if len(match) == 0 {
+// dateWarnings warns when the day of month never occurs in the months,
+// or occurs only on February 29. A restricted weekday runs on its days,
+// see matchDay.
+func dateWarnings(d []cronField) []string {
+	dayMonth := d[fieldDayMonth]
+	if dayMonth.star || !d[fieldWeekday].star {
+		return nil
+	}
+
+	occurs := func(month, days int) bool {
+		if dayMonth.lastDay || dayMonth.lastWeekday {
+			return true
+		}
+		for day := 1; day <= days; day++ {
+			if dayMonth.has(day) || dayMonth.nearest&(1<<uint(day)) != 0 {
+				return true
+			}
+		}
 		return false
 	}
 
-	return true
+	var months []string
+	leap := false
+	for month := 1; month <= 12; month++ {
+		if !d[fieldMonth].has(month) {
+			continue
+		}
+		days := normalDaySpec[month-1]
+		if occurs(month, days) {
+			return nil
+		}
+		if month == 2 && occurs(month, 29) {
+			leap = true
+		}
+		months = append(months, locales[English].months[month])
+	}
+
+	if leap {
+		return []string{fmt.Sprintf("Cron format day month %s occurs only on February 29 of the leap years", dayMonth.expr)}
+	}
+
+	return []string{fmt.Sprintf("Cron format day month %s never occurs in %s", dayMonth.expr, locales[English].list(months))}
 }
 
-func validateWeekday(s string) bool {
-	e := regexp.MustCompile(weekday)
-	match := e.FindAllString(s, -1)
-	if len(match) == 0 {
-		return false
+// fieldValue returns the value of a number or a name of the month
+// or the weekday, the range is not checked.
+func fieldValue(i int, s string) (int, bool) {
+	names := map[int]map[string]string{fieldMonth: monthNames, fieldWeekday: weekdayNames}[i]
+	if v, ok := names[s]; ok {
+		s = v
+	}
+
+	return number(s)
+}
+
+// nameSuggestion returns the name of the month or the weekday
+// which is meant by s, like JAN for January
+func nameSuggestion(i int, s string) string {
+	names := map[int]map[string]string{fieldMonth: monthNames, fieldWeekday: weekdayNames}[i]
+	var list []string
+	for name := range names {
+		if len(s) > 3 && strings.HasPrefix(s, name) {
+			return strings.ToUpper(name)
+		}
+		list = append(list, name)
+	}
+	sort.Strings(list)
+
+	return strings.ToUpper(closest(s, list, 1))
+}
+
+// number parses a number without a sign
+func number(s string) (int, bool) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, false
+	}
+	v, e := strconv.Atoi(s)
+
+	return v, e == nil
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+
+	return v
+}
+
+// closest returns the first of the words within the edit distance of s
+func closest(s string, words []string, distance int) string {
+	for _, word := range words {
+		if editDistance(s, word) <= distance {
+			return word
+		}
+	}
+
+	return ""
+}
+
+// editDistance is the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
 	}
 
-	return true
+	return a
 }
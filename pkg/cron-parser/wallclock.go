@@ -0,0 +1,74 @@
+package cronparser
+
+import (
+	"time"
+)
+
+// wallClock returns the wall clock of t in UTC, which has no daylight
+// saving time, so the fields can be matched with the plain arithmetic.
+func wallClock(t time.Time) time.Time {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+
+	return time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+}
+
+// instants returns the n times of the wall clock w in the location, ordered.
+// There is none when w is skipped by a gap, and there are two times when w
+// is repeated. The offsets are taken a day before and after w, a location
+// has at most one transition in two days.
+func instants(w time.Time, loc *time.Location) (times [2]time.Time, n int) {
+	for _, probe := range [2]time.Time{w.Add(-24 * time.Hour), w.Add(24 * time.Hour)} {
+		_, offset := probe.In(loc).Zone()
+		t := w.Add(-time.Duration(offset) * time.Second).In(loc)
+		if !wallClock(t).Equal(w) || n > 0 && times[0].Equal(t) {
+			continue
+		}
+		times[n] = t
+		n++
+	}
+
+	if n == 2 && times[1].Before(times[0]) {
+		times[0], times[1] = times[1], times[0]
+	}
+
+	return times, n
+}
+
+// transition returns the first time after lo with another offset than lo,
+// the offset of hi should be different.
+func transition(lo, hi time.Time, loc *time.Location) time.Time {
+	_, offset := lo.In(loc).Zone()
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+		if _, o := mid.In(loc).Zone(); o == offset {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return hi.In(loc)
+}
+
+// gapEnd returns the end of the gap which skips the wall clock w,
+// the first time after the spring-forward transition.
+func gapEnd(w time.Time, loc *time.Location) time.Time {
+	_, before := w.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := w.Add(24 * time.Hour).In(loc).Zone()
+
+	return transition(w.Add(-time.Duration(after)*time.Second), w.Add(-time.Duration(before)*time.Second), loc)
+}
+
+// fold returns the repeated wall clocks [start, end) when t is
+// in the first of them, before the fall-back transition.
+func fold(t time.Time) (start, end time.Time, ok bool) {
+	times, n := instants(wallClock(t), t.Location())
+	if n != 2 || !times[0].Equal(t.Truncate(time.Second)) {
+		return start, end, false
+	}
+
+	start = wallClock(transition(times[0], times[1], t.Location()))
+
+	return start, start.Add(times[1].Sub(times[0])), true
+}
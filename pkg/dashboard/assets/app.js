@@ -0,0 +1,157 @@
+(function () {
+  "use strict";
+
+  var selected = null;
+
+  function api(method, path, body) {
+    var opts = { method: method, headers: {} };
+    if (body !== undefined) {
+      opts.headers["Content-Type"] = "application/json";
+      opts.body = JSON.stringify(body);
+    }
+
+    return fetch("api" + path, opts).then(function (res) {
+      return res.json().then(function (data) {
+        if (!res.ok) {
+          throw new Error(data.error || res.statusText);
+        }
+        return data;
+      });
+    });
+  }
+
+  function el(tag, attrs, children) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (key === "text") {
+        node.textContent = attrs[key];
+      } else if (key === "onclick") {
+        node.addEventListener("click", attrs[key]);
+      } else {
+        node.setAttribute(key, attrs[key]);
+      }
+    });
+    (children || []).forEach(function (child) {
+      node.appendChild(child);
+    });
+
+    return node;
+  }
+
+  function formatTime(value) {
+    if (!value || value.indexOf("0001-01-01") === 0) {
+      return "-";
+    }
+
+    return new Date(value).toLocaleString();
+  }
+
+  function formatDuration(ns) {
+    var ms = ns / 1e6;
+    if (ms < 1000) {
+      return ms.toFixed(1) + " ms";
+    }
+
+    return (ms / 1000).toFixed(2) + " s";
+  }
+
+  function action(name, verb) {
+    return function (event) {
+      event.stopPropagation();
+      api("POST", "/jobs/" + encodeURIComponent(name) + "/" + verb)
+        .then(refresh)
+        .catch(function (e) {
+          alert(e.message);
+        });
+    };
+  }
+
+  function renderJobs(jobs) {
+    var tbody = document.querySelector("#jobs tbody");
+    tbody.innerHTML = "";
+    document.getElementById("empty").hidden = jobs.length > 0;
+
+    jobs.forEach(function (job) {
+      var toggle = job.paused
+        ? el("button", { text: "Resume", onclick: action(job.name, "resume") })
+        : el("button", { text: "Pause", onclick: action(job.name, "pause") });
+
+      tbody.appendChild(el("tr", { onclick: function () { showDetail(job.name); } }, [
+        el("td", {}, [
+          el("strong", { text: job.name }),
+          el("span", { class: "paused", text: job.paused ? " paused" : "" })
+        ]),
+        el("td", {}, [
+          el("div", { text: job.description || job.cron }),
+          el("div", { class: "cron", text: job.cron })
+        ]),
+        el("td", { text: formatTime(job.next_run) }),
+        el("td", { text: formatTime(job.last_run) }),
+        el("td", { text: String(job.total_run) }),
+        el("td", { text: job.success_rate + "%" }),
+        el("td", { text: job.error_rate + "%" }),
+        el("td", {}, [
+          toggle,
+          el("button", { text: "Trigger", onclick: action(job.name, "trigger") })
+        ])
+      ]));
+    });
+  }
+
+  function renderRuns(name, runs) {
+    var timeline = document.getElementById("timeline");
+    document.getElementById("detail").hidden = false;
+    document.getElementById("detail-name").textContent = name;
+    timeline.innerHTML = "";
+
+    if (runs.length === 0) {
+      timeline.appendChild(el("li", { text: "No runs yet." }));
+    }
+    runs.forEach(function (run) {
+      var children = [
+        el("div", {
+          text: run.skipped ? formatTime(run.scheduled_at) + " · " + (run.skipped_runs || 1) + " skipped by " + run.skipped :
+            formatTime(run.started_at) + " · " + formatDuration(run.duration) +
+            (run.manual ? " · triggered" : "")
+        })
+      ];
+      if (run.error) {
+        children.push(el("div", { class: "error", text: run.error }));
+      }
+      timeline.appendChild(el("li", { class: run.error ? "failed" : run.skipped ? "skipped" : "" }, children));
+    });
+  }
+
+  function showDetail(name) {
+    selected = name;
+    api("GET", "/jobs/" + encodeURIComponent(name) + "?runs=50").then(function (data) {
+      renderRuns(name, data.runs);
+    });
+  }
+
+  function refresh() {
+    api("GET", "/jobs").then(renderJobs);
+    if (selected) {
+      showDetail(selected);
+    }
+  }
+
+  function listen() {
+    var status = document.getElementById("status");
+    var source = new EventSource("events");
+
+    source.onopen = function () {
+      status.textContent = "live";
+      status.className = "status live";
+    };
+    source.onerror = function () {
+      status.textContent = "reconnecting";
+      status.className = "status";
+    };
+    source.addEventListener("run", refresh);
+  }
+
+  refresh();
+  listen();
+  setInterval(refresh, 30000);
+})();
@@ -0,0 +1,134 @@
+package dashboard
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+	admin "github.com/KodepandaID/shigoto/pkg/admin-api"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// KeepAlive is the interval of the comments which keep the event stream open
+var KeepAlive = 15 * time.Second
+
+// Scheduler is the part of shigoto.Config used by the dashboard
+type Scheduler interface {
+	admin.Scheduler
+	OnComplete(f func(shigoto.JobEvent)) *shigoto.Config
+}
+
+// Dashboard serves the web UI of a scheduler, the admin API at /api/
+// and the finished runs as server-sent events at /events.
+// It can be mounted on any router with http.StripPrefix.
+type Dashboard struct {
+	mux *http.ServeMux
+
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+}
+
+type runEvent struct {
+	RunID     string        `json:"run_id"`
+	JobName   string        `json:"job_name"`
+	Scheduled time.Time     `json:"scheduled_at"`
+	Started   time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Manual    bool          `json:"manual"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// New to create the dashboard of the scheduler
+func New(s Scheduler) *Dashboard {
+	d := &Dashboard{
+		mux:     http.NewServeMux(),
+		clients: make(map[chan []byte]struct{}),
+	}
+
+	static, _ := fs.Sub(assets, "assets")
+	d.mux.Handle("/", http.FileServer(http.FS(static)))
+	d.mux.Handle("/api/", http.StripPrefix("/api", admin.New(s)))
+	d.mux.HandleFunc("/events", d.events)
+	s.OnComplete(d.broadcast)
+
+	return d
+}
+
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mux.ServeHTTP(w, r)
+}
+
+// broadcast sends the run to every connected client,
+// a slow client misses the event instead of blocking the scheduler.
+func (d *Dashboard) broadcast(ev shigoto.JobEvent) {
+	run := runEvent{
+		RunID:     ev.RunID,
+		JobName:   ev.JobName,
+		Scheduled: ev.Scheduled,
+		Started:   ev.Started,
+		Duration:  ev.Duration,
+		Manual:    ev.Manual,
+	}
+	if ev.Error != nil {
+		run.Error = ev.Error.Error()
+	}
+	data, _ := json.Marshal(run)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for ch := range d.clients {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+func (d *Dashboard) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan []byte, 16)
+	d.mu.Lock()
+	d.clients[ch] = struct{}{}
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.clients, ch)
+		d.mu.Unlock()
+	}()
+
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(KeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case data := <-ch:
+			fmt.Fprintf(w, "event: run\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
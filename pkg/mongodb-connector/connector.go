@@ -14,19 +14,34 @@ import (
 
 // Connector mongodb instance
 type Connector struct {
-	DB     string        // The MongoDB uri
-	DBName string        // Database name from MongoDB
-	client *mongo.Client // Mongodb client
+	DB       string          // The MongoDB uri
+	DBName   string          // Database name from MongoDB
+	Observer Observer        // Called after every storage operation
+	Tracer   Tracer          // Starts a span for every storage operation
+	client   *mongo.Client   // Mongodb client
+	parent   context.Context // The parent of the operations, see WithContext
 }
 
+// Observer receives the name, latency and error of a storage operation
+type Observer func(op string, duration time.Duration, e error)
+
+// Tracer starts the span of a storage operation as a child of the ctx,
+// the returned function ends the span with the error of the operation.
+type Tracer func(ctx context.Context, op string) (context.Context, func(e error))
+
 type JobCollection struct {
 	ID          primitive.ObjectID `bson:"_id"`
 	JobName     string             `bson:"job_name"`
 	FuncName    string             `bson:"func_name"`
 	CronFormat  []string           `bson:"cron_format"`
+	Timezone    string             `bson:"timezone,omitempty"` // The time zone of the job, empty for the time zone of the scheduler
 	NextDate    time.Time          `bson:"next_date"`
+	LastDate    time.Time          `bson:"last_date"`
+	LastManual  bool               `bson:"last_manual"`
+	Paused      bool               `bson:"paused"`
 	TotalTask   int                `bson:"total_task"`
 	TotalRun    int                `bson:"total_run"`
+	TotalManual int                `bson:"total_manual"`
 	TotalError  int                `bson:"total_error"`
 	SuccessRate float64            `bson:"success_rate"`
 	ErrorRate   float64            `bson:"error_rate"`
@@ -37,6 +52,38 @@ type TaskCollection struct {
 	Params []interface{}      `bson:"params"`
 }
 
+// RunCollection is the history of a single job execution
+type RunCollection struct {
+	RunID       string             `bson:"run_id"`
+	JobId       primitive.ObjectID `bson:"job_id"`
+	JobName     string             `bson:"job_name"`
+	Params      []interface{}      `bson:"params"`
+	ScheduledAt time.Time          `bson:"scheduled_at"`
+	StartedAt   time.Time          `bson:"started_at"`
+	Duration    time.Duration      `bson:"duration"`
+	Manual      bool               `bson:"manual"`
+	Error       string             `bson:"error"`
+	Skipped     string             `bson:"skipped,omitempty"` // The reason the run was skipped, like the name of a blackout
+	// The runs skipped together and the scheduled time of the last one,
+	// the ScheduledAt is the scheduled time of the first one
+	SkippedRuns     int       `bson:"skipped_runs,omitempty"`
+	LastScheduledAt time.Time `bson:"last_scheduled_at,omitempty"`
+}
+
+// TriggerCollection is a run of a job requested by another process,
+// like the shigoto CLI. The scheduler runs it with the stored params
+// of the job when the params are empty.
+type TriggerCollection struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	JobName   string             `bson:"job_name"`
+	Params    []interface{}      `bson:"params"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// ErrJobRegistered is returned by InsertJobCollection with the id
+// of the job when the job name is already used
+var ErrJobRegistered = errors.New("Jobs is already registered, use the different job name")
+
 var ctx context.Context
 
 // New to create a new Mongodb connection
@@ -47,27 +94,62 @@ func New(c *Connector) (*Connector, error) {
 	}
 
 	return &Connector{
-		DB:     c.DB,
-		DBName: c.DBName,
-		client: client,
+		DB:       c.DB,
+		DBName:   c.DBName,
+		Observer: c.Observer,
+		Tracer:   c.Tracer,
+		client:   client,
 	}, nil
 }
 
+// WithContext returns a copy of the connector which runs the
+// operations as the children of the ctx, like the span of a job run.
+func (c *Connector) WithContext(ctx context.Context) *Connector {
+	cc := *c
+	cc.parent = ctx
+
+	return &cc
+}
+
+// begin starts an operation with a timeout. The returned function is
+// deferred with the named error result, it ends the span of the
+// operation and reports it to the Observer.
+func (c *Connector) begin(op string) (context.Context, func(e *error)) {
+	started := time.Now()
+	parent := c.parent
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	end := func(error) {}
+	if c.Tracer != nil {
+		parent, end = c.Tracer(parent, op)
+	}
+	ctx, cancel := context.WithTimeout(parent, 1*time.Second)
+
+	return ctx, func(e *error) {
+		cancel()
+		end(*e)
+		if c.Observer != nil {
+			c.Observer(op, time.Since(started), *e)
+		}
+	}
+}
+
 // Ping to check connection status
-func (c *Connector) Ping() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func (c *Connector) Ping() (err error) {
+	ctx, done := c.begin("ping")
+	defer done(&err)
 
 	e := c.client.Ping(ctx, readpref.Primary())
 
 	return e
 }
 
-func (c *Connector) GetJobCollection() ([]JobCollection, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func (c *Connector) GetJobCollection() (jobs []JobCollection, err error) {
+	ctx, done := c.begin("get_jobs")
+	defer done(&err)
 
-	var jobs []JobCollection
 	cursor, e := c.client.Database(c.DBName).Collection("jobs").Find(ctx, bson.M{})
 	if e != nil {
 		return jobs, e
@@ -78,25 +160,24 @@ func (c *Connector) GetJobCollection() ([]JobCollection, error) {
 	return jobs, nil
 }
 
-func (c *Connector) GetOneJobCollection(name string) (JobCollection, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func (c *Connector) GetOneJobCollection(name string) (jobs JobCollection, err error) {
+	ctx, done := c.begin("get_job")
+	defer done(&err)
 
-	var jobs JobCollection
 	e := c.client.Database(c.DBName).Collection("jobs").FindOne(ctx, bson.M{"job_name": name}).Decode(&jobs)
 
 	return jobs, e
 }
 
-func (c *Connector) InsertJobCollection(payload *JobCollection) (primitive.ObjectID, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func (c *Connector) InsertJobCollection(payload *JobCollection) (id primitive.ObjectID, err error) {
+	ctx, done := c.begin("insert_job")
+	defer done(&err)
 
 	var jobs bson.M
 	c.client.Database(c.DBName).Collection("jobs").FindOne(ctx, bson.M{"job_name": payload.JobName}).Decode(&jobs)
 
 	if jobs["job_name"] == payload.JobName {
-		return jobs["_id"].(primitive.ObjectID), errors.New("Jobs is already registered, use the different job name")
+		return jobs["_id"].(primitive.ObjectID), ErrJobRegistered
 	}
 
 	res, e := c.client.Database(c.DBName).
@@ -110,6 +191,9 @@ func (c *Connector) InsertJobCollection(payload *JobCollection) (primitive.Objec
 		}, {
 			Key:   "cron_format",
 			Value: payload.CronFormat,
+		}, {
+			Key:   "timezone",
+			Value: payload.Timezone,
 		}, {
 			Key:   "total_task",
 			Value: payload.TotalTask,
@@ -136,40 +220,102 @@ func (c *Connector) InsertJobCollection(payload *JobCollection) (primitive.Objec
 	return res.InsertedID.(primitive.ObjectID), nil
 }
 
-func (c *Connector) UpdateJobCollection(id primitive.ObjectID, payload *JobCollection, e int) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func (c *Connector) UpdateJobCollection(id primitive.ObjectID, payload *JobCollection, e int) (err error) {
+	ctx, done := c.begin("update_job")
+	defer done(&err)
+
+	manual := 0
+	if payload.LastManual {
+		manual = 1
+	}
+
+	set := bson.M{
+		"last_date":    payload.LastDate,
+		"last_manual":  payload.LastManual,
+		"success_rate": payload.SuccessRate,
+		"error_rate":   payload.ErrorRate,
+	}
+	// A manual run doesn't move the schedule, so the next_date is only
+	// updated when the caller computed a new one.
+	if !payload.NextDate.IsZero() {
+		set["next_date"] = payload.NextDate
+	}
+
+	filter := bson.M{"_id": bson.M{"$eq": id}}
+	update := bson.M{
+		"$inc": bson.M{"total_run": 1, "total_error": e, "total_manual": manual},
+		"$set": set,
+	}
+	_, err = c.client.
+		Database(c.DBName).
+		Collection("jobs").UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+// UpdateJobDefinition to replace the function name, cron format, time zone and next date of a job
+func (c *Connector) UpdateJobDefinition(id primitive.ObjectID, payload *JobCollection) (err error) {
+	ctx, done := c.begin("update_job_definition")
+	defer done(&err)
 
 	filter := bson.M{"_id": bson.M{"$eq": id}}
 	update := bson.M{
-		"$inc": bson.M{"total_run": 1, "total_error": e},
 		"$set": bson.M{
-			"next_date":    payload.NextDate,
-			"success_rate": payload.SuccessRate,
-			"error_rate":   payload.ErrorRate,
+			"func_name":   payload.FuncName,
+			"cron_format": payload.CronFormat,
+			"timezone":    payload.Timezone,
+			"next_date":   payload.NextDate,
 		},
 	}
-	c.client.
+	_, e := c.client.
 		Database(c.DBName).
 		Collection("jobs").UpdateOne(ctx, filter, update)
+
+	return e
 }
 
-func (c *Connector) DeleteJobCollection(name string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+// SetPaused to pause or resume a job
+func (c *Connector) SetPaused(name string, paused bool) (err error) {
+	ctx, done := c.begin("set_paused")
+	defer done(&err)
 
-	var jobs bson.M
-	c.client.Database(c.DBName).Collection("jobs").FindOne(ctx, bson.M{"job_name": name}).Decode(&jobs)
+	res, e := c.client.
+		Database(c.DBName).
+		Collection("jobs").UpdateOne(ctx, bson.M{"job_name": name}, bson.M{"$set": bson.M{"paused": paused}})
+	if e != nil {
+		return e
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
-	c.client.Database(c.DBName).Collection("jobs").DeleteOne(ctx, bson.M{"job_name": name})
-	c.client.Database(c.DBName).Collection("tasks").DeleteOne(ctx, bson.M{"job_id": bson.M{"$eq": jobs["_id"].(primitive.ObjectID)}})
+	return nil
+}
+
+func (c *Connector) DeleteJobCollection(name string) (err error) {
+	ctx, done := c.begin("delete_job")
+	defer done(&err)
+
+	var jobs JobCollection
+	if e := c.client.Database(c.DBName).Collection("jobs").FindOne(ctx, bson.M{"job_name": name}).Decode(&jobs); e != nil {
+		return e
+	}
+
+	if _, e := c.client.Database(c.DBName).Collection("jobs").DeleteOne(ctx, bson.M{"job_name": name}); e != nil {
+		return e
+	}
+	if _, e := c.client.Database(c.DBName).Collection("tasks").DeleteMany(ctx, bson.M{"job_id": bson.M{"$eq": jobs.ID}}); e != nil {
+		return e
+	}
+	_, e := c.client.Database(c.DBName).Collection("runs").DeleteMany(ctx, bson.M{"job_id": bson.M{"$eq": jobs.ID}})
+
+	return e
 }
 
-func (c *Connector) GetTasks(id primitive.ObjectID) ([]TaskCollection, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func (c *Connector) GetTasks(id primitive.ObjectID) (tasks []TaskCollection, err error) {
+	ctx, done := c.begin("get_tasks")
+	defer done(&err)
 
-	var tasks []TaskCollection
 	filter := bson.M{"job_id": bson.M{"$eq": id}}
 	cursor, e := c.client.Database(c.DBName).Collection("tasks").Find(ctx, filter)
 	if e != nil {
@@ -181,12 +327,12 @@ func (c *Connector) GetTasks(id primitive.ObjectID) ([]TaskCollection, error) {
 	return tasks, nil
 }
 
-func (c *Connector) InsertTask(id primitive.ObjectID, params ...interface{}) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+func (c *Connector) InsertTask(id primitive.ObjectID, params ...interface{}) (err error) {
+	ctx, done := c.begin("insert_task")
+	defer done(&err)
 
 	var jobs bson.M
-	filter := bson.M{"params": bson.M{"$eq": params}}
+	filter := bson.M{"job_id": bson.M{"$eq": id}, "params": bson.M{"$eq": params}}
 	c.client.Database(c.DBName).Collection("tasks").FindOne(ctx, filter).Decode(&jobs)
 
 	if jobs == nil {
@@ -212,3 +358,158 @@ func (c *Connector) InsertTask(id primitive.ObjectID, params ...interface{}) err
 
 	return nil
 }
+
+// DeleteTask to remove the task of a job with the params
+func (c *Connector) DeleteTask(id primitive.ObjectID, params ...interface{}) (err error) {
+	ctx, done := c.begin("delete_task")
+	defer done(&err)
+
+	filter := bson.M{"job_id": bson.M{"$eq": id}, "params": bson.M{"$eq": params}}
+	res, e := c.client.Database(c.DBName).Collection("tasks").DeleteOne(ctx, filter)
+	if e != nil {
+		return e
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	// Update total_task at jobs
+	filter = bson.M{"_id": bson.M{"$eq": id}}
+	update := bson.M{"$inc": bson.M{"total_task": -1}}
+	_, e = c.client.
+		Database(c.DBName).
+		Collection("jobs").UpdateOne(ctx, filter, update)
+
+	return e
+}
+
+// InsertRun to store the history of a job execution
+func (c *Connector) InsertRun(payload *RunCollection) (err error) {
+	ctx, done := c.begin("insert_run")
+	defer done(&err)
+
+	_, e := c.client.Database(c.DBName).
+		Collection("runs").
+		InsertOne(ctx, bson.D{{
+			Key:   "run_id",
+			Value: payload.RunID,
+		}, {
+			Key:   "job_id",
+			Value: payload.JobId,
+		}, {
+			Key:   "job_name",
+			Value: payload.JobName,
+		}, {
+			Key:   "params",
+			Value: payload.Params,
+		}, {
+			Key:   "scheduled_at",
+			Value: payload.ScheduledAt,
+		}, {
+			Key:   "started_at",
+			Value: payload.StartedAt,
+		}, {
+			Key:   "duration",
+			Value: payload.Duration,
+		}, {
+			Key:   "manual",
+			Value: payload.Manual,
+		}, {
+			Key:   "skipped",
+			Value: payload.Skipped,
+		}, {
+			Key:   "error",
+			Value: payload.Error,
+		}})
+
+	return e
+}
+
+// RecordSkipped to store a skipped run in the history of a job. The runs
+// skipped with the same RunID, like the runs in a blackout, are stored
+// as a single run with their count and the last scheduled time.
+func (c *Connector) RecordSkipped(payload *RunCollection) (err error) {
+	ctx, done := c.begin("record_skipped")
+	defer done(&err)
+
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"job_id":       payload.JobId,
+			"job_name":     payload.JobName,
+			"params":       payload.Params,
+			"scheduled_at": payload.ScheduledAt,
+			"started_at":   payload.StartedAt,
+			"duration":     time.Duration(0),
+			"manual":       false,
+			"error":        "",
+			"skipped":      payload.Skipped,
+		},
+		"$max": bson.M{"last_scheduled_at": payload.ScheduledAt},
+		"$inc": bson.M{"skipped_runs": 1},
+	}
+	_, e := c.client.Database(c.DBName).
+		Collection("runs").
+		UpdateOne(ctx, bson.M{"run_id": payload.RunID}, update, options.Update().SetUpsert(true))
+
+	return e
+}
+
+// GetRuns to get the latest execution history of a job, newest first
+func (c *Connector) GetRuns(name string, limit int64) (runs []RunCollection, err error) {
+	ctx, done := c.begin("get_runs")
+	defer done(&err)
+
+	opts := options.Find().SetSort(bson.M{"started_at": -1}).SetLimit(limit)
+	cursor, e := c.client.Database(c.DBName).Collection("runs").Find(ctx, bson.M{"job_name": name}, opts)
+	if e != nil {
+		return runs, e
+	}
+	defer cursor.Close(ctx)
+	cursor.All(ctx, &runs)
+
+	return runs, nil
+}
+
+// InsertTrigger to request a run of a job from another process
+func (c *Connector) InsertTrigger(name string, params ...interface{}) (err error) {
+	ctx, done := c.begin("insert_trigger")
+	defer done(&err)
+
+	_, e := c.client.Database(c.DBName).
+		Collection("triggers").
+		InsertOne(ctx, bson.D{{
+			Key:   "job_name",
+			Value: name,
+		}, {
+			Key:   "params",
+			Value: params,
+		}, {
+			Key:   "created_at",
+			Value: time.Now(),
+		}})
+
+	return e
+}
+
+// PopTriggers to get and remove the requested runs, oldest first.
+// Every trigger is claimed atomically, so a trigger is only returned
+// to one of the processes sharing the database. The claimed triggers
+// are returned with the error of a failed claim.
+func (c *Connector) PopTriggers() (triggers []TriggerCollection, err error) {
+	ctx, done := c.begin("pop_triggers")
+	defer done(&err)
+
+	collection := c.client.Database(c.DBName).Collection("triggers")
+	opts := options.FindOneAndDelete().SetSort(bson.M{"created_at": 1})
+	for {
+		var t TriggerCollection
+		e := collection.FindOneAndDelete(ctx, bson.M{}, opts).Decode(&t)
+		if e == mongo.ErrNoDocuments {
+			return triggers, nil
+		}
+		if e != nil {
+			return triggers, e
+		}
+		triggers = append(triggers, t)
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/KodepandaID/shigoto/pkg/otel-tracer
+
+go 1.16
+
+require (
+	github.com/KodepandaID/shigoto v0.0.0-20261019185557-a782e176103c
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+)
+
+// The shigoto of the repository is used while developing, the
+// replace is ignored by the modules which require the tracer
+replace github.com/KodepandaID/shigoto => ../..
@@ -0,0 +1,76 @@
+package oteltracer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the OpenTelemetry tracer
+const InstrumentationName = "github.com/KodepandaID/shigoto"
+
+// Tracer adapts an OpenTelemetry tracer to shigoto.Tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+type span struct {
+	span trace.Span
+}
+
+// New to create a Tracer from the provider,
+// the global provider is used when tp is nil.
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return &Tracer{
+		tracer: tp.Tracer(InstrumentationName),
+	}
+}
+
+// Start implements shigoto.Tracer
+func (t *Tracer) Start(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, shigoto.Span) {
+	kv := make([]attribute.KeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		kv = append(kv, toAttribute(k, v))
+	}
+
+	ctx, s := t.tracer.Start(ctx, name, trace.WithAttributes(kv...))
+	return ctx, &span{span: s}
+}
+
+func (s *span) SetError(e error) {
+	s.span.RecordError(e)
+	s.span.SetStatus(codes.Error, e.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+func toAttribute(k string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(k, v)
+	case int:
+		return attribute.Int(k, v)
+	case int64:
+		return attribute.Int64(k, v)
+	case bool:
+		return attribute.Bool(k, v)
+	case float64:
+		return attribute.Float64(k, v)
+	case time.Time:
+		return attribute.String(k, v.Format(time.RFC3339))
+	}
+
+	return attribute.String(k, fmt.Sprint(v))
+}
@@ -0,0 +1,56 @@
+package oteltracer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	oteltracer "github.com/KodepandaID/shigoto/pkg/otel-tracer"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOtelTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := oteltracer.New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, span := tracer.Start(context.Background(), "shigoto.run hello", map[string]interface{}{
+		"shigoto.job":       "hello",
+		"shigoto.attempt":   1,
+		"shigoto.scheduled": time.Date(2021, 5, 1, 1, 1, 0, 0, time.UTC),
+	})
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		t.Fatal("Context should carry the span")
+	}
+
+	_, child := tracer.Start(ctx, "shigoto.storage.get_job", nil)
+	child.End()
+
+	span.SetError(errors.New("Error"))
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Spans not match: %d", len(spans))
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("Storage span should be the child of the run span")
+		t.Fail()
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Error("Run span should have the error status")
+		t.Fail()
+	}
+
+	attrs := make(map[string]string)
+	for _, kv := range spans[1].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["shigoto.job"] != "hello" || attrs["shigoto.attempt"] != "1" || attrs["shigoto.scheduled"] != "2021-05-01T01:01:00Z" {
+		t.Errorf("Attributes not match: %v", attrs)
+		t.Fail()
+	}
+}
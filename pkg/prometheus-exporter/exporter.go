@@ -0,0 +1,221 @@
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the histograms
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Exporter collects the metrics of a scheduler and serves them
+// in the Prometheus text format. It implements shigoto.Metrics
+// and http.Handler.
+type Exporter struct {
+	config  *shigoto.Config
+	buckets []float64
+
+	mu       sync.Mutex
+	runs     map[string]*runMetrics
+	storage  map[string]*histogram
+	storeErr map[string]int
+}
+
+type runMetrics struct {
+	total    int
+	failures int
+	duration *histogram
+	lag      *histogram
+}
+
+type histogram struct {
+	counts []int // Not cumulative, counts[len(buckets)] is +Inf
+	sum    float64
+	count  int
+}
+
+// New to create an exporter and set it as the Metrics of the config
+func New(c *shigoto.Config) *Exporter {
+	e := &Exporter{
+		config:   c,
+		buckets:  DefaultBuckets,
+		runs:     make(map[string]*runMetrics),
+		storage:  make(map[string]*histogram),
+		storeErr: make(map[string]int),
+	}
+	c.Metrics = e
+
+	return e
+}
+
+// ObserveRun implements shigoto.Metrics
+func (e *Exporter) ObserveRun(job string, duration, lag time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := e.runs[job]
+	if r == nil {
+		r = &runMetrics{
+			duration: newHistogram(e.buckets),
+			lag:      newHistogram(e.buckets),
+		}
+		e.runs[job] = r
+	}
+
+	r.total++
+	if err != nil {
+		r.failures++
+	}
+	r.duration.observe(e.buckets, duration.Seconds())
+	r.lag.observe(e.buckets, lag.Seconds())
+}
+
+// ObserveStorage implements shigoto.Metrics
+func (e *Exporter) ObserveStorage(op string, duration time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	h := e.storage[op]
+	if h == nil {
+		h = newHistogram(e.buckets)
+		e.storage[op] = h
+	}
+	h.observe(e.buckets, duration.Seconds())
+
+	if err != nil {
+		e.storeErr[op]++
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	state := e.config.State()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b := &strings.Builder{}
+
+	jobs := sortedKeys(e.runs)
+	header(b, "shigoto_runs_total", "counter", "Total runs of a job.")
+	for _, job := range jobs {
+		fmt.Fprintf(b, "shigoto_runs_total{job=%s} %d\n", quote(job), e.runs[job].total)
+	}
+	header(b, "shigoto_run_failures_total", "counter", "Total runs of a job which returned an error.")
+	for _, job := range jobs {
+		fmt.Fprintf(b, "shigoto_run_failures_total{job=%s} %d\n", quote(job), e.runs[job].failures)
+	}
+	header(b, "shigoto_run_duration_seconds", "histogram", "Duration of the runs of a job.")
+	for _, job := range jobs {
+		e.runs[job].duration.write(b, "shigoto_run_duration_seconds", "job", job, e.buckets)
+	}
+	header(b, "shigoto_schedule_lag_seconds", "histogram", "Actual start minus the scheduled time of the runs of a job.")
+	for _, job := range jobs {
+		e.runs[job].lag.write(b, "shigoto_schedule_lag_seconds", "job", job, e.buckets)
+	}
+
+	header(b, "shigoto_next_run_timestamp_seconds", "gauge", "Unix time of the next run of a job.")
+	for _, job := range sortedKeys(state.NextRuns) {
+		// A job like @reboot has no next run after its run
+		if state.NextRuns[job].IsZero() {
+			continue
+		}
+		fmt.Fprintf(b, "shigoto_next_run_timestamp_seconds{job=%s} %d\n", quote(job), state.NextRuns[job].Unix())
+	}
+	header(b, "shigoto_registered_jobs", "gauge", "Number of jobs in the schedule.")
+	fmt.Fprintf(b, "shigoto_registered_jobs %d\n", len(state.NextRuns))
+	header(b, "shigoto_queue_depth", "gauge", "Number of runs which are due and not finished yet.")
+	fmt.Fprintf(b, "shigoto_queue_depth %d\n", state.QueueDepth)
+
+	ops := sortedKeys(e.storage)
+	header(b, "shigoto_storage_operation_duration_seconds", "histogram", "Latency of the persistent storage operations.")
+	for _, op := range ops {
+		e.storage[op].write(b, "shigoto_storage_operation_duration_seconds", "op", op, e.buckets)
+	}
+	header(b, "shigoto_storage_operation_errors_total", "counter", "Total persistent storage operations which returned an error.")
+	for _, op := range ops {
+		fmt.Fprintf(b, "shigoto_storage_operation_errors_total{op=%s} %d\n", quote(op), e.storeErr[op])
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{counts: make([]int, len(buckets)+1)}
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	i := sort.SearchFloat64s(buckets, v)
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(b *strings.Builder, name, label, value string, buckets []float64) {
+	var cumulative int
+	for i, le := range buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(b, "%s_bucket{%s=%s,le=\"%s\"} %d\n", name, label, quote(value), formatFloat(le), cumulative)
+	}
+	fmt.Fprintf(b, "%s_bucket{%s=%s,le=\"+Inf\"} %d\n", name, label, quote(value), h.count)
+	fmt.Fprintf(b, "%s_sum{%s=%s} %s\n", name, label, quote(value), formatFloat(h.sum))
+	fmt.Fprintf(b, "%s_count{%s=%s} %d\n", name, label, quote(value), h.count)
+}
+
+func header(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// quote escapes a label value with the Prometheus text format rules
+func quote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+
+	return `"` + s + `"`
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*runMetrics:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]time.Time:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
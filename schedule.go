@@ -2,32 +2,129 @@ package shigoto
 
 import (
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
+
+	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
 )
 
-// CronFormat to set a job with cron format
+// CronFormat to set a job with cron format or a macro like
+// `@hourly`, `@daily`, `@weekly`, `@monthly`, `@yearly`,
+// `@every 1h30m` and `@reboot`
 func (j *Jobs) CronFormat(cron string) *Jobs {
-	cronSlice := strings.Split(cron, " ")
-	j.Cron = cronSlice
+	j.Cron = strings.Fields(cron)
+
+	return j
+}
+
+// Timezone to run a job in a time zone like America/New_York instead of
+// the time zone of the scheduler, the fields of the cron format match the
+// wall clock of the time zone. A cron format starting with `CRON_TZ=`
+// has its own time zone.
+func (j *Jobs) Timezone(name string) *Jobs {
+	if _, e := loadTimezone(name); e != nil {
+		j.addErr(e)
+		return j
+	}
 
+	j.timezone = name
 	return j
 }
 
+// clock parses the `hh:mm` time of the builder, an invalid
+// time is kept as an error of the job.
+func (j *Jobs) clock(time string) (hour, minute int, ok bool) {
+	hour, minute, errs := parseClock(time)
+	for _, e := range errs {
+		j.addErr(e)
+	}
+
+	return hour, minute, len(errs) == 0
+}
+
+// parseClock parses a `hh:mm` time, it returns every error of the time
+func parseClock(time string) (hour, minute int, errs []error) {
+	parts := strings.Split(time, ":")
+	if len(parts) != 2 {
+		return 0, 0, []error{fmt.Errorf("The clock format %q is wrong, use hh:mm", time)}
+	}
+
+	hour, eHour := strconv.Atoi(parts[0])
+	minute, eMinute := strconv.Atoi(parts[1])
+	if eHour != nil || eMinute != nil {
+		return 0, 0, []error{fmt.Errorf("The clock format %q is wrong, use hh:mm", time)}
+	}
+
+	if hour < 0 || hour > 23 {
+		errs = append(errs, fmt.Errorf("The hour of %q is out of range 0-23", time))
+	}
+	if minute < 0 || minute > 59 {
+		errs = append(errs, fmt.Errorf("The minute of %q is out of range 0-59", time))
+	}
+
+	return hour, minute, errs
+}
+
 // At to run a job at a time
 func (j *Jobs) At(time string) *Jobs {
-	parts := strings.Split(time, ":")
-	if len(parts) < 2 {
-		log.Fatal("The clock format is wrong")
+	hour, minute, ok := j.clock(time)
+	if !ok {
+		return j
+	}
+
+	_, fields, _ := cronparser.SplitZone(j.Cron)
+	switch {
+	case len(fields) == 0:
+		j.addErr(fmt.Errorf("The time %q cannot be set on an empty cron format", time))
+		return j
+	case len(fields) < 5:
+		j.addErr(fmt.Errorf("The time %q cannot be set on the macro %s", time, fields[0]))
+		return j
 	}
 
-	hour, _ := strconv.Atoi(parts[0])
-	minute, _ := strconv.Atoi(parts[1])
+	// A cron format with the seconds field has the minute at index 1
+	i := len(j.Cron) - 5
+	j.Cron[i] = fmt.Sprintf("%d", minute)
+	j.Cron[i+1] = fmt.Sprintf("%d", hour)
+
+	return j
+}
+
+// EverySecond to run a job every seconds
+func (j *Jobs) EverySecond() *Jobs {
+	j.Cron = []string{"*", "*", "*", "*", "*", "*"}
+	return j
+}
+
+// EveryFiveSeconds to run a job every 5 seconds
+func (j *Jobs) EveryFiveSeconds() *Jobs {
+	return j.EverySeconds(5)
+}
+
+// EveryTenSeconds to run a job every 10 seconds
+func (j *Jobs) EveryTenSeconds() *Jobs {
+	return j.EverySeconds(10)
+}
+
+// EveryFifteenSeconds to run a job every 15 seconds
+func (j *Jobs) EveryFifteenSeconds() *Jobs {
+	return j.EverySeconds(15)
+}
+
+// EveryThirtySeconds to run a job every 30 seconds
+func (j *Jobs) EveryThirtySeconds() *Jobs {
+	return j.EverySeconds(30)
+}
 
-	j.Cron[0] = fmt.Sprintf("%d", minute)
-	j.Cron[1] = fmt.Sprintf("%d", hour)
+// EverySeconds to run a job every n seconds, the seconds restart
+// from 0 every minute so n should be a divisor of 60 for an even interval
+func (j *Jobs) EverySeconds(n int) *Jobs {
+	if n < 1 || n > 59 {
+		j.addErr(fmt.Errorf("The interval of %d seconds is out of range 1-59", n))
+		return j
+	}
 
+	j.Cron = []string{fmt.Sprintf("*/%d", n), "*", "*", "*", "*", "*"}
 	return j
 }
 
@@ -93,35 +190,29 @@ func (j *Jobs) Daily() *Jobs {
 
 // DailyAt to run a job every day at a specific time
 func (j *Jobs) DailyAt(time string) *Jobs {
-	parts := strings.Split(time, ":")
-	if len(parts) < 2 {
-		log.Fatal("The clock format is wrong")
+	hour, minute, ok := j.clock(time)
+	if !ok {
+		return j
 	}
 
-	hour, _ := strconv.Atoi(parts[0])
-	minute, _ := strconv.Atoi(parts[1])
-
 	j.Cron = []string{fmt.Sprint(minute), fmt.Sprint(hour), "*/1", "*", "*"}
 	return j
 }
 
-// Weekly to run a job every week
+// Weekly to run a job every week on Sunday at midnight
 func (j *Jobs) Weekly() *Jobs {
-	j.Cron = []string{"0", "0", "*/7", "*", "*"}
+	j.Cron = []string{"0", "0", "*", "*", "0"}
 	return j
 }
 
-// WeeklyOn to run a job every week at a specific time
+// WeeklyOn to run a job every week on Sunday at a specific time
 func (j *Jobs) WeeklyOn(time string) *Jobs {
-	parts := strings.Split(time, ":")
-	if len(parts) < 2 {
-		log.Fatal("The clock format is wrong")
+	hour, minute, ok := j.clock(time)
+	if !ok {
+		return j
 	}
 
-	hour, _ := strconv.Atoi(parts[0])
-	minute, _ := strconv.Atoi(parts[1])
-
-	j.Cron = []string{fmt.Sprint(minute), fmt.Sprint(hour), "*/7", "*", "*"}
+	j.Cron = []string{fmt.Sprint(minute), fmt.Sprint(hour), "*", "*", "0"}
 	return j
 }
 
@@ -133,21 +224,18 @@ func (j *Jobs) Monthly() *Jobs {
 
 // MonthlyOn to run a job every month at a specific time
 func (j *Jobs) MonthlyOn(time string) *Jobs {
-	parts := strings.Split(time, ":")
-	if len(parts) < 2 {
-		log.Fatal("The clock format is wrong")
+	hour, minute, ok := j.clock(time)
+	if !ok {
+		return j
 	}
 
-	hour, _ := strconv.Atoi(parts[0])
-	minute, _ := strconv.Atoi(parts[1])
-
 	j.Cron = []string{fmt.Sprint(minute), fmt.Sprint(hour), "1", "*/1", "*"}
 	return j
 }
 
-// Quarterly to run a job every 6 month
+// Quarterly to run a job every 3 months, on January, April, July and October 1
 func (j *Jobs) Quarterly() *Jobs {
-	j.Cron = []string{"0", "0", "1", "*/6", "*"}
+	j.Cron = []string{"0", "0", "1", "*/3", "*"}
 	return j
 }
 
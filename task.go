@@ -2,31 +2,78 @@ package shigoto
 
 import (
 	"errors"
-	"log"
+	"strings"
+	"sync"
 	"time"
 
 	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
 	"github.com/KodepandaID/shigoto/pkg/mongodb-connector"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // Config to set the configuration task scheduler
 type Config struct {
 	DB       string // The MongoDB uri
 	DBName   string // Database name from MongoDB
-	Timezone string
+	Timezone string // The default time zone of the jobs, Asia/Jakarta by default
 	Timeout  time.Duration
-	client   *mongodb.Connector
-	parser   cronparser.Parser
+	Logger   Logger  // The standard logger at the info level is used when it's nil
+	Metrics  Metrics // Receives the measurements of runs and storage operations
+	Tracer   Tracer  // Starts a span for every run and storage operation
+	// The language of the descriptions of the cron formats, English by default
+	Locale cronparser.Locale
+	// How often the changes made by another process, like the shigoto CLI,
+	// are loaded from the persistent storage. The default is 10 seconds.
+	SyncInterval time.Duration
+	client       *mongodb.Connector
+	parser       cronparser.Parser
+	loc          *time.Location
+	queued       int // The runs which are due and not finished, guarded by storageLock
+
+	hooks       hooks
+	middleware  []Middleware
+	blackouts   []blackout
+	jobOptions  map[string]*jobOptions
+	optionsLock sync.Mutex
+
+	running     map[string]chan struct{} // A job has a single run at a time
+	runningLock sync.Mutex
 }
 
+// ErrJobNotFound is returned when the job name is not registered
+var ErrJobNotFound = errors.New("Job is not registered")
+
+// ErrTaskRegistered is returned when the job already has a task with the params
+var ErrTaskRegistered = errors.New("Task with the same params is already registered")
+
+// ErrTaskNotFound is returned when the job has no task with the params
+var ErrTaskNotFound = errors.New("Task with the params is not registered")
+
 var ScheduleStorage = make(map[string]interface{})
 var FuncStorage = make(map[string]interface{})
 
+// storageLock guards ScheduleStorage, which is shared between
+// the background checker and the public API.
+var storageLock sync.Mutex
+
 // New to create task scheduler instance
 func New(c *Config) (*Config, error) {
+	// Cause I'm Indonesian I will be set the default timezone with Asia/Jakarta
+	if c.Timezone == "" {
+		c.Timezone = "Asia/Jakarta"
+	}
+	loc, e := loadTimezone(c.Timezone)
+	if e != nil {
+		return &Config{}, e
+	}
+	c.loc = loc
+
 	client, e := mongodb.New(&mongodb.Connector{
-		DB:     c.DB,
-		DBName: c.DBName,
+		DB:       c.DB,
+		DBName:   c.DBName,
+		Observer: c.observeStorage,
+		Tracer:   c.traceStorage,
 	})
 	if e != nil {
 		return &Config{}, e
@@ -37,26 +84,24 @@ func New(c *Config) (*Config, error) {
 	}
 	c.client = client
 
-	// Cause I'm Indonesian I will be set the default timezone with Asia/Jakarta
-	if c.Timezone == "" {
-		c.Timezone = "Asia/Jakarta"
+	if c.SyncInterval <= 0 {
+		c.SyncInterval = 10 * time.Second
 	}
 	c.parser = cronparser.New(&cronparser.Parser{
 		Timezone: c.Timezone,
 	})
 
-	LoadJobsFromPersistentStorage(c)
+	if e := LoadJobsFromPersistentStorage(c); e != nil {
+		return &Config{}, e
+	}
 
 	return c, nil
 }
 
 // Command to create a new job process
 func (c *Config) Command(jobName, funcName string, params ...interface{}) *Jobs {
-	if jobName == "" {
-		log.Fatal("The job's name cannot be empty")
-	}
-
-	return &Jobs{
+	j := &Jobs{
+		config:    c,
 		client:    c.client,
 		parser:    &c.parser,
 		JobName:   jobName,
@@ -64,6 +109,11 @@ func (c *Config) Command(jobName, funcName string, params ...interface{}) *Jobs
 		JobParams: params,
 		Cron:      []string{"*", "*", "*", "*", "*"},
 	}
+	if jobName == "" {
+		j.addErr(errors.New("The job's name cannot be empty"))
+	}
+
+	return j
 }
 
 // Register to register a function to call with the name
@@ -73,19 +123,214 @@ func (c *Config) Register(funcName string, jobFunc interface{}) {
 }
 
 // Delete to remove job from instance and persistent storage
-func (c *Config) Delete(name string) {
-	for key, jobs := range ScheduleStorage {
-		j := jobs.([]map[string]interface{})
-		j, match := checkSameJobName(name, j)
-		if match {
-			if len(j) > 0 {
-				ScheduleStorage[key] = j
-			} else {
-				delete(ScheduleStorage, key)
+func (c *Config) Delete(name string) error {
+	storageLock.Lock()
+	removed := removeTasks(name)
+	storageLock.Unlock()
+
+	if e := c.client.DeleteJobCollection(name); e != nil {
+		if e == mongo.ErrNoDocuments && len(removed) == 0 {
+			return ErrJobNotFound
+		}
+		c.logger().Error("storage error", "op", "delete_job", "job", name, "error", e)
+		return e
+	}
+	c.logger().Info("job deleted", "job", name)
+
+	return nil
+}
+
+// Pause to stop the scheduled runs of a job until it's resumed.
+// The job can still be started with Trigger.
+func (c *Config) Pause(name string) error {
+	return c.setPaused(name, true)
+}
+
+// Resume to start the scheduled runs of a paused job again
+func (c *Config) Resume(name string) error {
+	return c.setPaused(name, false)
+}
+
+func (c *Config) setPaused(name string, paused bool) error {
+	if e := c.client.SetPaused(name, paused); e != nil {
+		if e == mongo.ErrNoDocuments {
+			return ErrJobNotFound
+		}
+		return e
+	}
+
+	storageLock.Lock()
+	for _, jobs := range ScheduleStorage {
+		for _, task := range jobs.([]map[string]interface{}) {
+			if task["job_name"].(string) == name {
+				task["paused"] = paused
 			}
-			c.client.DeleteJobCollection(name)
 		}
 	}
+	storageLock.Unlock()
+	c.logger().Info("job paused", "job", name, "paused", paused)
+
+	return nil
+}
+
+// AddTask to add a new params of a registered job,
+// the job runs once for every params. The params are
+// removed by the next Do of the job.
+func (c *Config) AddTask(name string, params ...interface{}) error {
+	job, e := c.client.GetOneJobCollection(name)
+	if e != nil {
+		if e == mongo.ErrNoDocuments {
+			return ErrJobNotFound
+		}
+		return e
+	}
+
+	cron := cronparser.WithZone(job.CronFormat, job.Timezone)
+	next, e := nextRun(c, name, cron, job.ID.Timestamp(), time.Now().In(c.loc))
+	if e != nil {
+		return e
+	}
+	if scheduled := findTasks(name); len(scheduled) > 0 {
+		next = scheduled[0]["next"].(time.Time)
+	}
+
+	task, e := newTask(job.ID.Hex(), name, job.FuncName, params, cron, next)
+	if e != nil {
+		return e
+	}
+	task["paused"] = job.Paused
+
+	storageLock.Lock()
+	added := scheduleTask(task)
+	storageLock.Unlock()
+	if !added {
+		return ErrTaskRegistered
+	}
+
+	return c.client.InsertTask(job.ID, params...)
+}
+
+// RemoveTask to remove the params of a registered job
+func (c *Config) RemoveTask(name string, params ...interface{}) error {
+	job, e := c.client.GetOneJobCollection(name)
+	if e != nil {
+		if e == mongo.ErrNoDocuments {
+			return ErrJobNotFound
+		}
+		return e
+	}
+
+	if e := c.client.DeleteTask(job.ID, params...); e != nil {
+		if e == mongo.ErrNoDocuments {
+			return ErrTaskNotFound
+		}
+		return e
+	}
+
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	for _, task := range removeTasks(name) {
+		if !sameParams(task["params"].([]interface{}), params) {
+			scheduleTask(task)
+		}
+	}
+
+	return nil
+}
+
+// Reschedule to change the cron format of a registered job.
+// The job keeps its time zone unless the cron format starts with `CRON_TZ=`.
+// The job is moved to its new next run immediately.
+func (c *Config) Reschedule(name, cron string) error {
+	job, e := c.client.GetOneJobCollection(name)
+	if e != nil {
+		if e == mongo.ErrNoDocuments {
+			return ErrJobNotFound
+		}
+		return e
+	}
+
+	expr := strings.Fields(cron)
+	timezone := job.Timezone
+	if zone, _, ok := cronparser.SplitZone(expr); ok {
+		timezone = zone
+	}
+	next, e := nextRun(c, name, cronparser.WithZone(expr, timezone), job.ID.Timestamp(), time.Now().In(c.loc))
+	if e != nil {
+		return e
+	}
+
+	return c.reschedule(job.ID, name, job.FuncName, expr, timezone, next)
+}
+
+// reschedule persists the new definition of a job and moves
+// all of its tasks in the schedule storage to the next run.
+func (c *Config) reschedule(id primitive.ObjectID, name, funcName string, cron []string, timezone string, next time.Time) error {
+	zoned := cronparser.WithZone(cron, timezone)
+	expr, e := cronparser.CompileFields(zoned)
+	if e != nil {
+		return e
+	}
+
+	if e := c.client.UpdateJobDefinition(id, &mongodb.JobCollection{
+		FuncName:   funcName,
+		CronFormat: cron,
+		Timezone:   timezone,
+		NextDate:   next,
+	}); e != nil {
+		return e
+	}
+
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	for _, task := range removeTasks(name) {
+		task["func_name"] = funcName
+		task["cron"] = zoned
+		task["expr"] = expr
+		task["next"] = next
+		delete(task, "scheduled")
+		scheduleTask(task)
+	}
+	c.logger().Info("job rescheduled", "job", name, "cron", strings.Join(cron, " "), "next", next)
+
+	return nil
+}
+
+// Trigger to run a job immediately outside its schedule.
+// The job runs once for every stored parameter set, or once with
+// overrideParams when they are given. The next scheduled time is unchanged.
+// The run waits until the running scheduled or triggered run of the job ends,
+// a scheduled run is skipped while the job is running.
+func (c *Config) Trigger(name string, overrideParams ...interface{}) error {
+	tasks := findTasks(name)
+	if len(tasks) == 0 {
+		return ErrJobNotFound
+	}
+
+	if len(overrideParams) > 0 {
+		task := copyTask(tasks[0])
+		task["params"] = overrideParams
+		tasks = []map[string]interface{}{task}
+	}
+
+	storageLock.Lock()
+	c.queued += len(tasks)
+	storageLock.Unlock()
+
+	tnow := time.Now().In(c.loc)
+	slot := c.runSlot(name)
+	for _, task := range tasks {
+		c.logger().Info("job triggered", "job", name, "params", task["params"])
+		go func(task map[string]interface{}) {
+			slot <- struct{}{}
+			defer func() { <-slot }()
+			dispatch(c, task, tnow, true)
+		}(task)
+	}
+
+	return nil
 }
 
 // Run n a background process to check the tasks
@@ -0,0 +1,179 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KodepandaID/shigoto"
+	admin "github.com/KodepandaID/shigoto/pkg/admin-api"
+	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
+)
+
+var _ admin.Scheduler = &shigoto.Config{}
+
+type fakeScheduler struct {
+	calls []string
+	args  []interface{}
+}
+
+func (f *fakeScheduler) call(name string, args ...interface{}) error {
+	f.calls = append(f.calls, name)
+	f.args = args
+	if len(args) > 0 && args[0] == "unknown" {
+		return shigoto.ErrJobNotFound
+	}
+	return nil
+}
+
+func (f *fakeScheduler) List() ([]shigoto.JobInfo, error) {
+	f.call("list")
+	return []shigoto.JobInfo{{Name: "hello"}}, nil
+}
+
+func (f *fakeScheduler) Get(name string) (shigoto.JobInfo, error) {
+	return shigoto.JobInfo{Name: name}, f.call("get", name)
+}
+
+func (f *fakeScheduler) History(name string, limit int) ([]shigoto.RunInfo, error) {
+	return []shigoto.RunInfo{{JobName: name}}, f.call("history", name, limit)
+}
+
+func (f *fakeScheduler) Pause(name string) error  { return f.call("pause", name) }
+func (f *fakeScheduler) Resume(name string) error { return f.call("resume", name) }
+func (f *fakeScheduler) Delete(name string) error { return f.call("delete", name) }
+
+func (f *fakeScheduler) Trigger(name string, params ...interface{}) error {
+	return f.call("trigger", append([]interface{}{name}, params...)...)
+}
+
+func (f *fakeScheduler) Reschedule(name, cron string) error {
+	if e := f.call("reschedule", name, cron); e != nil {
+		return e
+	}
+	if _, e := cronparser.Validate(strings.Fields(cron)); e != nil {
+		return fmt.Errorf("Job %q cannot be rescheduled: %w", name, e)
+	}
+	return nil
+}
+
+func (f *fakeScheduler) AddTask(name string, params ...interface{}) error {
+	if e := f.call("add_task", append([]interface{}{name}, params...)...); e != nil {
+		return e
+	}
+	if len(params) > 0 && params[0] == "registered" {
+		return fmt.Errorf("Job %q: %w", name, shigoto.ErrTaskRegistered)
+	}
+	return nil
+}
+
+func (f *fakeScheduler) RemoveTask(name string, params ...interface{}) error {
+	if e := f.call("remove_task", append([]interface{}{name}, params...)...); e != nil {
+		return e
+	}
+	if len(params) > 0 && params[0] == "missing" {
+		return shigoto.ErrTaskNotFound
+	}
+	if len(params) > 0 && params[0] == "outage" {
+		return errors.New("Task with a storage outage")
+	}
+	return nil
+}
+
+// callScheduler runs the registered function on Trigger
+type callScheduler struct {
+	fakeScheduler
+}
+
+func (c *callScheduler) Trigger(name string, params ...interface{}) error {
+	c.call("trigger", append([]interface{}{name}, params...)...)
+	return shigoto.CallFuncWithParams(name, params)
+}
+
+func TestAdminAPIIntegerParams(t *testing.T) {
+	var sum int
+	shigoto.FuncStorage["admin-sum"] = func(a, b int) error {
+		sum = a + b
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	admin.New(&callScheduler{}).ServeHTTP(rec, httptest.NewRequest("POST", "/jobs/admin-sum/trigger", strings.NewReader(`{"params": [2, 3]}`)))
+	if rec.Code != http.StatusAccepted || sum != 5 {
+		t.Errorf("trigger status is %d and the sum is %d: %s", rec.Code, sum, rec.Body.String())
+	}
+}
+
+func TestAdminAPI(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		call   string
+		args   []interface{}
+	}{
+		{"GET", "/jobs", "", http.StatusOK, "list", nil},
+		{"GET", "/jobs/hello?runs=5", "", http.StatusOK, "history", []interface{}{"hello", 5}},
+		{"GET", "/jobs/unknown", "", http.StatusNotFound, "get", []interface{}{"unknown"}},
+		{"DELETE", "/jobs/hello", "", http.StatusOK, "delete", []interface{}{"hello"}},
+		{"POST", "/jobs/hello/pause", "", http.StatusOK, "pause", []interface{}{"hello"}},
+		{"POST", "/jobs/hello/resume", "", http.StatusOK, "resume", []interface{}{"hello"}},
+		{"POST", "/jobs/hello/trigger", "", http.StatusAccepted, "trigger", []interface{}{"hello"}},
+		{"POST", "/jobs/hello/trigger", `{"params": ["usman"]}`, http.StatusAccepted, "trigger", []interface{}{"hello", "usman"}},
+		{"PUT", "/jobs/hello/schedule", `{"cron": "0 * * * *"}`, http.StatusOK, "reschedule", []interface{}{"hello", "0 * * * *"}},
+		{"POST", "/jobs/hello/tasks", `{"params": ["usman"]}`, http.StatusCreated, "add_task", []interface{}{"hello", "usman"}},
+		{"DELETE", "/jobs/hello/tasks", `{"params": ["usman"]}`, http.StatusOK, "remove_task", []interface{}{"hello", "usman"}},
+		{"PUT", "/jobs/hello/schedule", `{"cron": "60 * * * *"}`, http.StatusBadRequest, "reschedule", []interface{}{"hello", "60 * * * *"}},
+		{"POST", "/jobs/hello/tasks", `{"params": ["registered"]}`, http.StatusBadRequest, "add_task", []interface{}{"hello", "registered"}},
+		{"DELETE", "/jobs/hello/tasks", `{"params": ["missing"]}`, http.StatusBadRequest, "remove_task", []interface{}{"hello", "missing"}},
+		{"DELETE", "/jobs/hello/tasks", `{"params": ["outage"]}`, http.StatusInternalServerError, "remove_task", []interface{}{"hello", "outage"}},
+		{"PUT", "/jobs/hello/schedule", `{"cron":`, http.StatusBadRequest, "", nil},
+		{"GET", "/jobs/hello/pause", "", http.StatusMethodNotAllowed, "", nil},
+		{"GET", "/tasks", "", http.StatusNotFound, "", nil},
+	}
+
+	for _, test := range tests {
+		f := &fakeScheduler{}
+		rec := httptest.NewRecorder()
+		admin.New(f).ServeHTTP(rec, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+
+		if rec.Code != test.status {
+			t.Errorf("%s %s status is %d status should be %d", test.method, test.path, rec.Code, test.status)
+			t.Fail()
+		}
+		if test.call == "" {
+			if len(f.calls) > 0 {
+				t.Errorf("%s %s should not call the scheduler", test.method, test.path)
+				t.Fail()
+			}
+			continue
+		}
+		if len(f.calls) == 0 || f.calls[len(f.calls)-1] != test.call || !reflect.DeepEqual(f.args, test.args) {
+			t.Errorf("%s %s calls %v %v", test.method, test.path, f.calls, f.args)
+			t.Fail()
+		}
+	}
+}
+
+func TestAdminAPIGetJob(t *testing.T) {
+	rec := httptest.NewRecorder()
+	admin.New(&fakeScheduler{}).ServeHTTP(rec, httptest.NewRequest("GET", "/jobs/hello", nil))
+
+	var body struct {
+		Job  shigoto.JobInfo   `json:"job"`
+		Runs []shigoto.RunInfo `json:"runs"`
+	}
+	if e := json.NewDecoder(rec.Body).Decode(&body); e != nil {
+		t.Fatal(e)
+	}
+	if body.Job.Name != "hello" || len(body.Runs) != 1 {
+		t.Errorf("Body not match: %+v", body)
+		t.Fail()
+	}
+}
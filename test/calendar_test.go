@@ -0,0 +1,120 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+)
+
+const holidays = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20210812
+DTEND;VALUE=DATE:20210813
+SUMMARY:Islamic New Year
+END:VEVENT
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20210101
+RRULE:FREQ=YEARLY
+SUMMARY:New Year
+END:VEVENT
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20210512
+DTEND;VALUE=DATE:20210515
+SUMMARY:Eid al-Fitr
+  Holiday
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestCalendar(t *testing.T) {
+	cal := shigoto.NewCalendar("indonesia").
+		ExcludeWeekdays(time.Saturday, time.Sunday).
+		ExcludeDates(time.Date(2021, 8, 17, 0, 0, 0, 0, time.UTC)).
+		ExcludeEveryYear(time.December, 25)
+
+	excluded := map[string]bool{
+		"2021-08-14": true,  // Saturday
+		"2021-08-16": false, // Monday
+		"2021-08-17": true,
+		"2021-12-24": false,
+		"2021-12-25": true,
+		"2022-12-25": true,
+	}
+	for day, want := range excluded {
+		d, _ := time.Parse("2006-01-02", day)
+		if cal.Excludes(d) != want {
+			t.Errorf("%s should be excluded: %t", day, want)
+		}
+	}
+}
+
+func TestCalendarImportICS(t *testing.T) {
+	cal := shigoto.NewCalendar("indonesia")
+	if e := cal.ImportICS(strings.NewReader(holidays)); e != nil {
+		t.Fatal(e)
+	}
+
+	excluded := map[string]bool{
+		"2021-08-12": true,
+		"2021-08-13": false, // DTEND is not included
+		"2021-01-01": true,
+		"2025-01-01": true, // A yearly event
+		"2021-05-12": true,
+		"2021-05-14": true,
+		"2021-05-15": false,
+		"2022-05-12": false,
+	}
+	for day, want := range excluded {
+		d, _ := time.Parse("2006-01-02", day)
+		if cal.Excludes(d) != want {
+			t.Errorf("%s should be excluded: %t", day, want)
+		}
+	}
+
+	invalid := []string{
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:2021\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nSUMMARY:No date\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20210101\nRRULE:FREQ=WEEKLY\nEND:VEVENT\n",
+	}
+	for _, ics := range invalid {
+		if e := shigoto.NewCalendar("invalid").ImportICS(strings.NewReader(ics)); e == nil {
+			t.Errorf("%q should be an error", ics)
+		}
+	}
+}
+
+func TestNextRunsOnlyOnBusinessDays(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	holiday := shigoto.NewCalendar("holiday").ExcludeDates(tomorrow)
+
+	client.Register("hello", helloWithoutParams)
+	if _, e = client.Command("run-hello-business-days", "hello").DailyAt("09:00").OnlyOnBusinessDays(holiday).Do(); e != nil {
+		t.Fatal(e)
+	}
+	defer client.Delete("run-hello-business-days")
+
+	runs, e := client.NextRuns("run-hello-business-days", 10)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(runs) != 10 {
+		t.Fatalf("there should be 10 runs, got %d", len(runs))
+	}
+	for _, run := range runs {
+		if run.Weekday() == time.Saturday || run.Weekday() == time.Sunday || holiday.Excludes(run) {
+			t.Errorf("%s is not a business day", run)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/KodepandaID/shigoto"
@@ -41,6 +42,22 @@ func TestCallFuncParamsError(t *testing.T) {
 	}
 }
 
+func TestCallFuncConvertParams(t *testing.T) {
+	var got []int64
+	shigoto.FuncStorage["hello-call-func-numbers"] = func(a int, b int32, rest ...int64) error {
+		got = append([]int64{int64(a), int64(b)}, rest...)
+		return nil
+	}
+
+	// The numbers of JSON are float64 and the numbers of BSON are int32
+	if e := shigoto.CallFuncWithParams("hello-call-func-numbers", []interface{}{float64(5), int32(6), float64(7), 8}); e != nil {
+		t.Fatal(e)
+	}
+	if want := []int64{5, 6, 7, 8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("params are %v, they should be %v", got, want)
+	}
+}
+
 func helloCallFunc() error {
 	return nil
 }
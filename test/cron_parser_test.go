@@ -1,6 +1,8 @@
 package test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -127,8 +129,23 @@ func TestValidateMonth(t *testing.T) {
 	}
 }
 
+func TestValidateMonthNames(t *testing.T) {
+	str := []string{"JAN", "dec", "Jan-Jun", "JAN,APR,JUL,OCT", "feb-nov/3"}
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+	})
+
+	for _, row := range str {
+		expr := []string{"*", "*", "*", row, "*"}
+		if _, e := parser.Parse(expr); e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+	}
+}
+
 func TestValidateFailMonth(t *testing.T) {
-	str := []string{"13", "0", "1-13"}
+	str := []string{"13", "0", "1-13", "JANUARY"}
 	parser := cronparser.New(&cronparser.Parser{
 		Timezone: "Asia/Jakarta",
 	})
@@ -141,14 +158,14 @@ func TestValidateFailMonth(t *testing.T) {
 		}
 	}
 
-	if etotal < 3 {
+	if etotal < 4 {
 		t.Error("This test should be fail")
 		t.Fail()
 	}
 }
 
 func TestValidateWeekday(t *testing.T) {
-	str := []string{"*", "0", "6"}
+	str := []string{"*", "0", "6", "7", "1-7", "MON-FRI", "sun,Wed,SAT"}
 	parser := cronparser.New(&cronparser.Parser{
 		Timezone: "Asia/Jakarta",
 	})
@@ -163,7 +180,7 @@ func TestValidateWeekday(t *testing.T) {
 }
 
 func TestValidateFailWeekday(t *testing.T) {
-	str := []string{"7", "ABC", "1-7"}
+	str := []string{"8", "ABC", "1-8", "MONDAY"}
 	parser := cronparser.New(&cronparser.Parser{
 		Timezone: "Asia/Jakarta",
 	})
@@ -176,12 +193,95 @@ func TestValidateFailWeekday(t *testing.T) {
 		}
 	}
 
-	if etotal < 3 {
+	if etotal < 4 {
 		t.Error("This test should be fail")
 		t.Fail()
 	}
 }
 
+func TestValidateSecond(t *testing.T) {
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+	})
+
+	for _, row := range []string{"*", "*/7", "0", "59", "1-5", "0,30"} {
+		expr := []string{row, "*", "*", "*", "*", "*"}
+		if _, e := parser.Parse(expr); e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+	}
+
+	for _, row := range []string{"60", "1,60", "0-60", "*/0"} {
+		expr := []string{row, "*", "*", "*", "*", "*"}
+		if _, e := parser.Parse(expr); e == nil {
+			t.Errorf("Second %s should be fail", row)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseEveryStepSecond(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2021, 5, 1, 1, 1, 40, 0, loc)
+
+	valueTest := []string{
+		"2021-05-01 01:01:45 +0700 WIB",
+		"2021-05-01 01:02:00 +0700 WIB",
+		"2021-05-01 01:02:15 +0700 WIB",
+		"2021-05-01 01:02:30 +0700 WIB",
+	}
+	expr := []string{"*/15", "*", "*", "*", "*", "*"}
+
+	for _, val := range valueTest {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  now,
+		})
+		s, e := parser.Parse(expr)
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+
+		if s.Next.String() != val {
+			t.Errorf("Value test not match: value is %s value should be %s", s.Next.String(), val)
+			t.Fail()
+		}
+		now = s.Next
+	}
+}
+
+func TestParseSecondOfMinute(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2021, 5, 1, 1, 4, 50, 0, loc)
+
+	valueTest := []string{
+		"2021-05-01 01:05:10 +0700 WIB",
+		"2021-05-01 01:05:40 +0700 WIB",
+		"2021-05-01 02:05:10 +0700 WIB",
+	}
+	expr := []string{"10,40", "5", "*", "*", "*", "*"}
+
+	for _, val := range valueTest {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  now,
+		})
+		s, e := parser.Parse(expr)
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+
+		if s.Next.String() != val {
+			t.Errorf("Value test not match: value is %s value should be %s", s.Next.String(), val)
+			t.Fail()
+		}
+		now = s.Next
+	}
+}
+
 func TestParseEveryMinute(t *testing.T) {
 	loc, _ := time.LoadLocation("Asia/Jakarta")
 	now := time.Date(2021, 5, 1, 1, 1, 0, 0, loc)
@@ -311,10 +411,10 @@ func TestParseEveryStepHourEveryMinute(t *testing.T) {
 	now := time.Date(2021, 5, 1, 1, 1, 0, 0, loc)
 
 	valueTest := []string{
-		"2021-05-01 01:02:00 +0700 WIB",
-		"2021-05-01 01:03:00 +0700 WIB",
-		"2021-05-01 01:04:00 +0700 WIB",
-		"2021-05-01 01:05:00 +0700 WIB",
+		"2021-05-01 02:00:00 +0700 WIB",
+		"2021-05-01 02:01:00 +0700 WIB",
+		"2021-05-01 02:02:00 +0700 WIB",
+		"2021-05-01 02:03:00 +0700 WIB",
 	}
 	expr := []string{"*", "*/2", "*", "*", "*"}
 
@@ -383,11 +483,12 @@ func TestParseEveryStepDayMonth(t *testing.T) {
 		time.Date(2021, 3, 4, 23, 59, 0, 0, loc),
 	}
 
+	// The days of */2 are 1, 3, 5, ... 31
 	valueTest := []string{
-		"2021-03-02 23:59:00 +0700 WIB",
-		"2021-03-04 00:00:00 +0700 WIB",
-		"2021-03-04 00:01:00 +0700 WIB",
-		"2021-03-06 00:00:00 +0700 WIB",
+		"2021-03-03 00:00:00 +0700 WIB",
+		"2021-03-03 00:00:00 +0700 WIB",
+		"2021-03-05 00:00:00 +0700 WIB",
+		"2021-03-05 00:00:00 +0700 WIB",
 	}
 
 	expr := []string{"*", "*", "*/2", "*", "*"}
@@ -486,10 +587,11 @@ func TestParseEveryStepMonth(t *testing.T) {
 		time.Date(2021, 4, 1, 1, 0, 0, 0, loc),
 	}
 
+	// The months of */2 are 1, 3, 5, ... 11
 	valueTest := []string{
-		"2021-02-02 00:00:00 +0700 WIB",
-		"2021-04-01 00:00:00 +0700 WIB",
-		"2021-04-01 01:01:00 +0700 WIB",
+		"2021-03-01 00:00:00 +0700 WIB",
+		"2021-03-04 00:00:00 +0700 WIB",
+		"2021-05-01 00:00:00 +0700 WIB",
 	}
 
 	expr := []string{"*", "*", "*", "*/2", "*"}
@@ -683,3 +785,810 @@ func TestParseValueWeek(t *testing.T) {
 		}
 	}
 }
+
+func TestParseList(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2021, 5, 1, 1, 1, 0, 0, loc)
+
+	valueTest := []string{
+		"2021-05-01 01:15:00 +0700 WIB",
+		"2021-05-01 01:30:00 +0700 WIB",
+		"2021-05-01 09:00:00 +0700 WIB",
+		"2021-05-01 09:15:00 +0700 WIB",
+		"2021-05-01 09:30:00 +0700 WIB",
+		"2021-05-02 01:00:00 +0700 WIB",
+	}
+	expr := []string{"0,15,30", "1,9", "*", "*", "*"}
+
+	for _, val := range valueTest {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  now,
+		})
+		s, e := parser.Parse(expr)
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+
+		if s.Next.String() != val {
+			t.Errorf("Value test not match: value is %s value should be %s", s.Next.String(), val)
+			t.Fail()
+		}
+		now = s.Next
+	}
+}
+
+func TestParseStepOfRange(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2021, 5, 1, 1, 1, 0, 0, loc)
+
+	tests := []struct {
+		expr  []string
+		value []string
+	}{
+		{
+			[]string{"10-30/10", "*", "*", "*", "*"},
+			[]string{"2021-05-01 01:10:00 +0700 WIB", "2021-05-01 01:20:00 +0700 WIB", "2021-05-01 01:30:00 +0700 WIB", "2021-05-01 02:10:00 +0700 WIB"},
+		},
+		{
+			[]string{"45/5", "*", "*", "*", "*"},
+			[]string{"2021-05-01 01:45:00 +0700 WIB", "2021-05-01 01:50:00 +0700 WIB", "2021-05-01 01:55:00 +0700 WIB", "2021-05-01 02:45:00 +0700 WIB"},
+		},
+		{
+			[]string{"0", "8-18/4,22", "*", "*", "*"},
+			[]string{"2021-05-01 08:00:00 +0700 WIB", "2021-05-01 12:00:00 +0700 WIB", "2021-05-01 16:00:00 +0700 WIB", "2021-05-01 22:00:00 +0700 WIB"},
+		},
+	}
+
+	for _, test := range tests {
+		tnow := now
+		for _, val := range test.value {
+			parser := cronparser.New(&cronparser.Parser{
+				Timezone: "Asia/Jakarta",
+				SetTime:  tnow,
+			})
+			s, e := parser.Parse(test.expr)
+			if e != nil {
+				t.Error(e)
+				t.Fail()
+			}
+
+			if s.Next.String() != val {
+				t.Errorf("%v not match: value is %s value should be %s", test.expr, s.Next.String(), val)
+				t.Fail()
+			}
+			tnow = s.Next
+		}
+	}
+}
+
+func TestParseDayMonthOrWeekday(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, loc)
+
+	// The 13th or every Friday
+	valueTest := []string{
+		"2021-03-05 00:00:00 +0700 WIB",
+		"2021-03-12 00:00:00 +0700 WIB",
+		"2021-03-13 00:00:00 +0700 WIB",
+		"2021-03-19 00:00:00 +0700 WIB",
+	}
+	expr := []string{"0", "0", "13", "*", "5"}
+
+	for _, val := range valueTest {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  now,
+		})
+		s, e := parser.Parse(expr)
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+
+		if s.Next.String() != val {
+			t.Errorf("Value test not match: value is %s value should be %s", s.Next.String(), val)
+			t.Fail()
+		}
+		now = s.Next
+	}
+}
+
+func TestParseInvalidField(t *testing.T) {
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+	})
+
+	exprs := [][]string{
+		{"30-10", "*", "*", "*", "*"},
+		{"*", "*/0", "*", "*", "*"},
+		{"0", "0", "30", "2", "*"},
+	}
+	for _, expr := range exprs {
+		if _, e := parser.Parse(expr); e == nil {
+			t.Errorf("%v should be fail", expr)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseNames(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	nowTimeCollection := []time.Time{
+		time.Date(2021, 3, 5, 9, 0, 0, 0, loc),
+		time.Date(2021, 3, 5, 9, 0, 0, 0, loc),
+		time.Date(2021, 3, 5, 9, 0, 0, 0, loc),
+		time.Date(2021, 3, 5, 9, 0, 0, 0, loc),
+	}
+
+	valueTest := []string{
+		"2021-03-08 09:00:00 +0700 WIB",
+		"2021-04-01 00:00:00 +0700 WIB",
+		"2021-03-07 00:00:00 +0700 WIB",
+		"2021-03-06 00:00:00 +0700 WIB",
+	}
+
+	exprs := [][]string{
+		{"0", "9", "*", "*", "Mon-FRI"},
+		{"0", "0", "1", "jan,APR,Jul,oct", "*"},
+		{"0", "0", "*", "*", "7"},
+		{"0", "0", "*", "*", "SAT-7"},
+	}
+
+	for i, expr := range exprs {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  nowTimeCollection[i],
+		})
+		s, e := parser.Parse(expr)
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+
+		if s.Next.String() != valueTest[i] {
+			t.Errorf("%v not match: value is %s value should be %s", expr, s.Next.String(), valueTest[i])
+			t.Fail()
+		}
+	}
+}
+
+func TestParseMacro(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2021, 3, 17, 1, 30, 0, 0, loc)
+
+	tests := map[string]string{
+		"@yearly":   "2022-01-01 00:00:00 +0700 WIB",
+		"@annually": "2022-01-01 00:00:00 +0700 WIB",
+		"@monthly":  "2021-04-01 00:00:00 +0700 WIB",
+		"@weekly":   "2021-03-21 00:00:00 +0700 WIB",
+		"@daily":    "2021-03-18 00:00:00 +0700 WIB",
+		"@MIDNIGHT": "2021-03-18 00:00:00 +0700 WIB",
+		"@hourly":   "2021-03-17 02:00:00 +0700 WIB",
+	}
+
+	for macro, val := range tests {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  now,
+		})
+		s, e := parser.Parse([]string{macro})
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+
+		if s.Next.String() != val {
+			t.Errorf("%s not match: value is %s value should be %s", macro, s.Next.String(), val)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseEvery(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	anchor := time.Date(2021, 3, 17, 1, 10, 0, 0, loc)
+	now := time.Date(2021, 3, 17, 5, 0, 0, 0, loc)
+
+	// The intervals start at the anchor: 02:40, 04:10, 05:40, ...
+	valueTest := []string{
+		"2021-03-17 05:40:00 +0700 WIB",
+		"2021-03-17 07:10:00 +0700 WIB",
+		"2021-03-17 08:40:00 +0700 WIB",
+	}
+	expr := []string{"@every", "1h30m"}
+
+	for _, val := range valueTest {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  now,
+			Anchor:   anchor,
+		})
+		s, e := parser.Parse(expr)
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+		}
+
+		if s.Next.String() != val {
+			t.Errorf("Value test not match: value is %s value should be %s", s.Next.String(), val)
+			t.Fail()
+		}
+		now = s.Next
+	}
+}
+
+func TestParseReboot(t *testing.T) {
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+	})
+	s, e := parser.Parse([]string{"@reboot"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !s.Next.IsZero() || !cronparser.IsReboot([]string{"@REBOOT"}) {
+		t.Errorf("@reboot should not have a next time: %s", s.Next)
+		t.Fail()
+	}
+}
+
+func TestParseInvalidMacro(t *testing.T) {
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+	})
+
+	exprs := [][]string{
+		{"@often"},
+		{"@daily", "*"},
+		{"@every"},
+		{"@every", "ten"},
+		{"@every", "500ms"},
+	}
+	for _, expr := range exprs {
+		if _, e := parser.Parse(expr); e == nil {
+			t.Errorf("%v should be fail", expr)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseModifier(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+
+	tests := []struct {
+		expr  []string
+		now   time.Time
+		value string
+	}{
+		// The last day of February in the leap years
+		{[]string{"0", "0", "L", "2", "*"}, time.Date(2023, 1, 1, 0, 0, 0, 0, loc), "2023-02-28 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "L", "2", "*"}, time.Date(2024, 1, 1, 0, 0, 0, 0, loc), "2024-02-29 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "L", "2", "*"}, time.Date(2100, 1, 1, 0, 0, 0, 0, loc), "2100-02-28 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "L", "2", "*"}, time.Date(2000, 1, 1, 0, 0, 0, 0, loc), "2000-02-29 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "L", "*", "*"}, time.Date(2021, 4, 30, 0, 0, 0, 0, loc), "2021-05-31 00:00:00 +0700 WIB"},
+		// The nearest weekday doesn't leave the month
+		{[]string{"0", "0", "15W", "*", "*"}, time.Date(2021, 5, 1, 0, 0, 0, 0, loc), "2021-05-14 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "15W", "*", "*"}, time.Date(2021, 8, 1, 0, 0, 0, 0, loc), "2021-08-16 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "1W", "*", "*"}, time.Date(2021, 4, 2, 0, 0, 0, 0, loc), "2021-05-03 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "31W", "*", "*"}, time.Date(2021, 1, 1, 0, 0, 0, 0, loc), "2021-01-29 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "31W", "*", "*"}, time.Date(2021, 1, 30, 0, 0, 0, 0, loc), "2021-03-31 00:00:00 +0700 WIB"},
+		// The last weekday of the month
+		{[]string{"0", "0", "LW", "*", "*"}, time.Date(2021, 2, 1, 0, 0, 0, 0, loc), "2021-02-26 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "LW", "*", "*"}, time.Date(2020, 2, 1, 0, 0, 0, 0, loc), "2020-02-28 00:00:00 +0700 WIB"},
+		// The last Friday and Sunday of the month
+		{[]string{"0", "0", "*", "*", "5L"}, time.Date(2021, 2, 1, 0, 0, 0, 0, loc), "2021-02-26 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "*", "*", "7L"}, time.Date(2021, 3, 1, 0, 0, 0, 0, loc), "2021-03-28 00:00:00 +0700 WIB"},
+		{[]string{"0", "0", "*", "*", "friL"}, time.Date(2020, 2, 1, 0, 0, 0, 0, loc), "2020-02-28 00:00:00 +0700 WIB"},
+		// The second Tuesday and the fifth Monday of the month
+		{[]string{"0", "9", "*", "*", "2#2"}, time.Date(2021, 3, 1, 0, 0, 0, 0, loc), "2021-03-09 09:00:00 +0700 WIB"},
+		{[]string{"0", "9", "*", "*", "TUE#2"}, time.Date(2021, 3, 9, 9, 0, 0, 0, loc), "2021-04-13 09:00:00 +0700 WIB"},
+		{[]string{"0", "9", "*", "*", "1#5"}, time.Date(2021, 1, 1, 0, 0, 0, 0, loc), "2021-03-29 09:00:00 +0700 WIB"},
+	}
+
+	for _, test := range tests {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  test.now,
+		})
+		s, e := parser.Parse(test.expr)
+		if e != nil {
+			t.Error(e)
+			t.Fail()
+			continue
+		}
+
+		if s.Next.String() != test.value {
+			t.Errorf("%v not match: value is %s value should be %s", test.expr, s.Next.String(), test.value)
+			t.Fail()
+		}
+	}
+}
+
+func TestValidateFailModifier(t *testing.T) {
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+	})
+
+	exprs := [][]string{
+		{"0", "0", "W", "*", "*"},
+		{"0", "0", "32W", "*", "*"},
+		{"0", "0", "L-3", "*", "*"},
+		{"0", "0", "*", "*", "8L"},
+		{"0", "0", "*", "*", "1#6"},
+		{"0", "0", "*", "*", "1#0"},
+		{"L", "0", "*", "*", "*"},
+	}
+	for _, expr := range exprs {
+		if _, e := parser.Parse(expr); e == nil {
+			t.Errorf("%v should be fail", expr)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseDaylightSaving(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		tz    string
+		expr  []string
+		now   time.Time
+		value []string
+	}{
+		// The spring-forward gap from 02:00 to 03:00 runs a fixed time once at 03:00
+		{"Europe/Berlin", []string{"0", "2", "*", "*", "*"}, time.Date(2021, 3, 27, 3, 0, 0, 0, berlin), []string{
+			"2021-03-28 03:00:00 +0200 CEST",
+			"2021-03-29 02:00:00 +0200 CEST",
+		}},
+		{"Europe/Berlin", []string{"30", "2", "*", "*", "*"}, time.Date(2021, 3, 28, 1, 0, 0, 0, berlin), []string{
+			"2021-03-28 03:00:00 +0200 CEST",
+			"2021-03-29 02:30:00 +0200 CEST",
+		}},
+		{"America/New_York", []string{"0", "2", "*", "*", "*"}, time.Date(2021, 3, 13, 12, 0, 0, 0, newYork), []string{
+			"2021-03-14 03:00:00 -0400 EDT",
+			"2021-03-15 02:00:00 -0400 EDT",
+		}},
+		// A wildcard continues at its next wall clock after the gap
+		{"Europe/Berlin", []string{"15", "*", "*", "*", "*"}, time.Date(2021, 3, 28, 1, 0, 0, 0, berlin), []string{
+			"2021-03-28 01:15:00 +0100 CET",
+			"2021-03-28 03:15:00 +0200 CEST",
+		}},
+		{"Europe/Berlin", []string{"*/30", "*", "*", "*", "*"}, time.Date(2021, 3, 28, 1, 15, 0, 0, berlin), []string{
+			"2021-03-28 01:30:00 +0100 CET",
+			"2021-03-28 03:00:00 +0200 CEST",
+			"2021-03-28 03:30:00 +0200 CEST",
+		}},
+		// The fall-back repeats 02:00 to 03:00, a fixed time runs once
+		{"Europe/Berlin", []string{"30", "2", "*", "*", "*"}, time.Date(2021, 10, 30, 3, 0, 0, 0, berlin), []string{
+			"2021-10-31 02:30:00 +0200 CEST",
+			"2021-11-01 02:30:00 +0100 CET",
+		}},
+		// A wildcard runs in both of the repeated hours
+		{"Europe/Berlin", []string{"30", "*", "*", "*", "*"}, time.Date(2021, 10, 31, 1, 0, 0, 0, berlin), []string{
+			"2021-10-31 01:30:00 +0200 CEST",
+			"2021-10-31 02:30:00 +0200 CEST",
+			"2021-10-31 02:30:00 +0100 CET",
+			"2021-10-31 03:30:00 +0100 CET",
+		}},
+		// A daily job doesn't drift across the transitions
+		{"Europe/Berlin", []string{"0", "0", "*", "*", "*"}, time.Date(2021, 3, 27, 0, 0, 0, 0, berlin), []string{
+			"2021-03-28 00:00:00 +0100 CET",
+			"2021-03-29 00:00:00 +0200 CEST",
+		}},
+		{"Europe/Berlin", []string{"0", "12", "*", "*", "*"}, time.Date(2021, 10, 30, 12, 0, 0, 0, berlin), []string{
+			"2021-10-31 12:00:00 +0100 CET",
+			"2021-11-01 12:00:00 +0100 CET",
+		}},
+	}
+
+	for _, test := range tests {
+		now := test.now
+		for _, val := range test.value {
+			parser := cronparser.New(&cronparser.Parser{
+				Timezone: test.tz,
+				SetTime:  now,
+			})
+			s, e := parser.Parse(test.expr)
+			if e != nil {
+				t.Error(e)
+				t.Fail()
+				break
+			}
+
+			if s.Next.String() != val {
+				t.Errorf("%v not match: value is %s value should be %s", test.expr, s.Next.String(), val)
+				t.Fail()
+			}
+			now = s.Next
+		}
+	}
+}
+
+func TestScheduleNextN(t *testing.T) {
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+		SetTime:  time.Date(2021, 1, 29, 10, 0, 0, 0, time.UTC),
+	})
+	s, e := parser.Parse([]string{"0", "9", "L", "*", "*"})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	value := []string{
+		"2021-01-31 09:00:00 +0700 WIB",
+		"2021-02-28 09:00:00 +0700 WIB",
+		"2021-03-31 09:00:00 +0700 WIB",
+	}
+	times := s.NextN(s.Next.Add(-time.Second), 3)
+	if len(times) != len(value) {
+		t.Fatalf("NextN returns %d times, it should be %d", len(times), len(value))
+	}
+	for i, val := range value {
+		if times[i].String() != val {
+			t.Errorf("value is %s value should be %s", times[i].String(), val)
+		}
+	}
+}
+
+func TestSchedulePrev(t *testing.T) {
+	tests := []struct {
+		tz   string
+		expr []string
+		from time.Time
+	}{
+		{"Asia/Jakarta", []string{"*/15", "9-17", "*", "*", "1-5"}, time.Date(2021, 1, 29, 10, 0, 0, 0, time.UTC)},
+		{"Asia/Jakarta", []string{"0", "0", "29", "2", "*"}, time.Date(2021, 1, 29, 10, 0, 0, 0, time.UTC)},
+		{"Asia/Jakarta", []string{"*/10", "*", "*", "*", "*", "*"}, time.Date(2021, 1, 29, 10, 0, 0, 0, time.UTC)},
+		{"Europe/Berlin", []string{"30", "2", "*", "*", "*"}, time.Date(2021, 3, 26, 0, 0, 0, 0, time.UTC)},
+		{"Europe/Berlin", []string{"30", "2", "*", "*", "*"}, time.Date(2021, 10, 29, 0, 0, 0, 0, time.UTC)},
+		{"Europe/Berlin", []string{"*/20", "*", "*", "*", "*"}, time.Date(2021, 10, 30, 23, 0, 0, 0, time.UTC)},
+	}
+
+	// Prev walks back the times of NextN, across the daylight saving time
+	for _, test := range tests {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: test.tz,
+			SetTime:  test.from,
+		})
+		s, e := parser.Parse(test.expr)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		times := s.NextN(test.from, 12)
+		for i := len(times) - 1; i > 0; i-- {
+			if prev := s.Prev(times[i]); !prev.Equal(times[i-1]) {
+				t.Errorf("%v prev of %s is %s, it should be %s", test.expr, times[i], prev, times[i-1])
+			}
+		}
+	}
+}
+
+func TestScheduleBetween(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+	})
+	s, e := parser.Parse([]string{"0", "*/6", "*", "*", "*"})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	times := s.Between(time.Date(2021, 1, 1, 0, 0, 0, 0, jakarta), time.Date(2021, 1, 2, 0, 0, 0, 0, jakarta))
+	if len(times) != 4 {
+		t.Fatalf("Between returns %d times, it should be 4", len(times))
+	}
+	if times[0].String() != "2021-01-01 06:00:00 +0700 WIB" || times[3].String() != "2021-01-02 00:00:00 +0700 WIB" {
+		t.Errorf("Between returns %v", times)
+	}
+}
+
+func TestScheduleMatches(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	tests := []struct {
+		expr  []string
+		t     time.Time
+		value bool
+	}{
+		{[]string{"30", "9", "*", "*", "mon-fri"}, time.Date(2021, 1, 29, 9, 30, 0, 0, jakarta), true},
+		{[]string{"30", "9", "*", "*", "mon-fri"}, time.Date(2021, 1, 30, 9, 30, 0, 0, jakarta), false},
+		{[]string{"30", "9", "*", "*", "mon-fri"}, time.Date(2021, 1, 29, 9, 30, 1, 0, jakarta), false},
+		{[]string{"30", "9", "*", "*", "mon-fri"}, time.Date(2021, 1, 29, 2, 30, 0, 0, time.UTC), true},
+		{[]string{"0", "0", "L", "*", "*"}, time.Date(2024, 2, 29, 0, 0, 0, 0, jakarta), true},
+		{[]string{"@reboot"}, time.Date(2024, 2, 29, 0, 0, 0, 0, jakarta), false},
+	}
+
+	for _, test := range tests {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+		})
+		s, e := parser.Parse(test.expr)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if s.Matches(test.t) != test.value {
+			t.Errorf("%v matches %s should be %v", test.expr, test.t, test.value)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		expr       string
+		english    string
+		indonesian string
+	}{
+		{"30 9 * * 1-5", "At 09:30, Monday through Friday", "Pada pukul 09:30, Senin sampai Jumat"},
+		{"*/15 * * * *", "Every 15 minutes", "Setiap 15 menit"},
+		{"0 */2 * * *", "Every 2 hours", "Setiap 2 jam"},
+		{"*/10 * * * * *", "Every 10 seconds", "Setiap 10 detik"},
+		{"*/5 9-17 * * MON-FRI", "Every 5 minutes, between 09:00 and 17:59, Monday through Friday",
+			"Setiap 5 menit, antara pukul 09:00 dan 17:59, Senin sampai Jumat"},
+		{"0 9,17 * * *", "At 09:00 and 17:00", "Pada pukul 09:00 dan 17:00"},
+		{"0 9 1 JAN,APR,JUL,OCT *", "At 09:00, on day 1 of the month, only in January, April, July and October",
+			"Pada pukul 09:00, pada tanggal 1, hanya pada bulan Januari, April, Juli dan Oktober"},
+		{"0 9 L * *", "At 09:00, on the last day of the month", "Pada pukul 09:00, pada hari terakhir setiap bulan"},
+		{"0 9 15W * *", "At 09:00, on the weekday nearest day 15 of the month",
+			"Pada pukul 09:00, pada hari kerja terdekat dengan tanggal 15"},
+		{"0 18 * * 5L", "At 18:00, on the last Friday of the month", "Pada pukul 18:00, pada hari Jumat terakhir setiap bulan"},
+		{"0 9 * * 2#2", "At 09:00, on the second Tuesday of the month", "Pada pukul 09:00, pada hari Selasa kedua setiap bulan"},
+		{"@daily", "At 00:00", "Pada pukul 00:00"},
+		{"@hourly", "Every hour", "Setiap jam"},
+		{"@every 1h30m", "Every 1h30m", "Setiap 1h30m"},
+		{"@reboot", "Once every time the scheduler starts", "Sekali setiap kali penjadwal dimulai"},
+	}
+
+	for _, test := range tests {
+		english, e := cronparser.Describe(strings.Fields(test.expr))
+		if e != nil {
+			t.Error(e)
+			continue
+		}
+		if english != test.english {
+			t.Errorf("%s is described as %q, it should be %q", test.expr, english, test.english)
+		}
+
+		indonesian, _ := cronparser.DescribeLocale(strings.Fields(test.expr), cronparser.Indonesian)
+		if indonesian != test.indonesian {
+			t.Errorf("%s is described as %q, it should be %q", test.expr, indonesian, test.indonesian)
+		}
+	}
+
+	if _, e := cronparser.Describe([]string{"60", "*", "*", "*", "*"}); e == nil {
+		t.Error("Describe of an incorrect cron format should return an error")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	tests := []struct {
+		expr       string
+		field      string
+		token      string
+		offset     int
+		suggestion string
+	}{
+		{"60 * * * *", "minute", "60", 0, "59 * * * *"},
+		{"0 9 * *", "", "0 9 * *", 0, "0 9 * * *"},
+		{"0 22-2 * * *", "hour", "22-2", 2, "0 22-23,0-2 * * *"},
+		{"0 0 1,32 * *", "day month", "32", 6, "0 0 1,31 * *"},
+		{"0 0 * JANUARY *", "month", "JANUARY", 6, "0 0 * JAN *"},
+		{"0 0 * * MOM", "weekday", "MOM", 8, "0 0 * * MON"},
+		{"0 0 * * 1#6", "weekday", "1#6", 8, "0 0 * * 1#5"},
+		{"0 0 * * 7-1", "weekday", "7-1", 8, "0 0 * * 0-1"},
+		{"0 0 * * 6-2", "weekday", "6-2", 8, "0 0 * * 6-6,0-2"},
+		{"*/0 * * * *", "minute", "*/0", 0, "* * * * *"},
+		{"0 0 1/24 * * *", "hour", "1/24", 4, "0 0 1/23 * * *"},
+		{"@dialy", "macro", "@dialy", 0, "@daily"},
+		{"@every 10ms", "macro", "10ms", 7, "@every 1s"},
+	}
+
+	for _, test := range tests {
+		_, e := cronparser.Validate(strings.Fields(test.expr))
+		invalid, ok := e.(*cronparser.ValidationError)
+		if !ok {
+			t.Errorf("%s error should be a ValidationError: %v", test.expr, e)
+			continue
+		}
+		if invalid.Field != test.field || invalid.Token != test.token || invalid.Offset != test.offset || invalid.Suggestion != test.suggestion {
+			t.Errorf("%s error is %+v", test.expr, invalid)
+		}
+	}
+
+	// A step up to the max of the field is valid, like every 6 minutes
+	for _, expr := range []string{"*/6 * * * *", "*/45 * * * *", "0 */12 * * *"} {
+		if _, e := cronparser.Validate(strings.Fields(expr)); e != nil {
+			t.Error(e)
+		}
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	tests := []struct {
+		expr     string
+		warnings int
+	}{
+		{"0 0 30 2 *", 1},
+		{"0 0 31 4,6,9,11 *", 1},
+		{"0 0 29 2 *", 1},
+		{"0 0 30 2 1", 0},
+		{"0 0 31 * *", 0},
+		{"0 0 L 2 *", 0},
+		{"@daily", 0},
+	}
+
+	for _, test := range tests {
+		warnings, e := cronparser.Validate(strings.Fields(test.expr))
+		if e != nil {
+			t.Error(e)
+			continue
+		}
+		if len(warnings) != test.warnings {
+			t.Errorf("%s warnings are %v", test.expr, warnings)
+		}
+	}
+}
+
+func TestExpression(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	from := time.Date(2021, 1, 29, 10, 0, 0, 0, jakarta)
+
+	// Next of an expression is the Next of the parser
+	for _, expr := range []string{"*/15 9-17 * * MON-FRI", "0 9 L * *", "0 18 * * 5L", "*/10 * * * * *", "@weekly", "0 0 29 2 *"} {
+		x, e := cronparser.Compile(expr)
+		if e != nil {
+			t.Fatal(e)
+		}
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+			SetTime:  from,
+		})
+		s, e := parser.Parse(strings.Fields(expr))
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if next := x.Next(from); !next.Equal(s.Next) {
+			t.Errorf("%s next is %s, it should be %s", expr, next, s.Next)
+		}
+		if x.String() != expr {
+			t.Errorf("%s string is %s", expr, x.String())
+		}
+	}
+
+	equal := [][2]string{
+		{"0 9 * * MON-FRI", "0 9 * * 1-5"},
+		{"@daily", "0 0 * * *"},
+		{"0 0 * * 7", "0 0 * * 0"},
+		{"0 0 9 * * *", "0 9 * * *"},
+	}
+	for _, pair := range equal {
+		a, _ := cronparser.Compile(pair[0])
+		b, _ := cronparser.Compile(pair[1])
+		if !a.Equal(b) {
+			t.Errorf("%s should be equal to %s", pair[0], pair[1])
+		}
+	}
+
+	a, _ := cronparser.Compile("0 9 * * *")
+	b, _ := cronparser.Compile("0 10 * * *")
+	if a.Equal(b) {
+		t.Error("0 9 * * * should not be equal to 0 10 * * *")
+	}
+
+	if _, e := cronparser.Compile("0 24 * * *"); e == nil {
+		t.Error("Compile of an incorrect cron format should return an error")
+	}
+}
+
+func TestExpressionTimezone(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	newYork, _ := time.LoadLocation("America/New_York")
+	from := time.Date(2021, 3, 1, 10, 0, 0, 0, jakarta)
+
+	// 09:00 in New York is 21:00 in Jakarta during the winter
+	x, e := cronparser.Compile("CRON_TZ=America/New_York 0 9 * * *")
+	if e != nil {
+		t.Fatal(e)
+	}
+	next := x.Next(from)
+	if want := time.Date(2021, 3, 1, 9, 0, 0, 0, newYork); !next.Equal(want) || next.Location().String() != "America/New_York" {
+		t.Errorf("next is %s, it should be %s", next, want)
+	}
+
+	// and 20:00 in Jakarta after the spring-forward transition
+	next = x.Next(time.Date(2021, 3, 15, 0, 0, 0, 0, jakarta))
+	if want := time.Date(2021, 3, 15, 20, 0, 0, 0, jakarta); !next.Equal(want) {
+		t.Errorf("next is %s, it should be %s", next.In(jakarta), want)
+	}
+
+	parser := cronparser.New(&cronparser.Parser{
+		Timezone: "Asia/Jakarta",
+		SetTime:  from,
+	})
+	s, e := parser.Parse([]string{"TZ=America/New_York", "0", "9", "*", "*", "*"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if want := time.Date(2021, 3, 1, 9, 0, 0, 0, newYork); !s.Next.Equal(want) {
+		t.Errorf("parser next is %s, it should be %s", s.Next, want)
+	}
+	if !s.Matches(time.Date(2021, 3, 2, 9, 0, 0, 0, newYork)) {
+		t.Error("09:00 in New York should match")
+	}
+
+	a, _ := cronparser.Compile("CRON_TZ=America/New_York 0 9 * * *")
+	b, _ := cronparser.Compile("0 9 * * *")
+	if a.Equal(b) {
+		t.Error("the expressions in different time zones should not be equal")
+	}
+
+	_, e = cronparser.Compile("CRON_TZ=Mars/Olympus 0 9 * * *")
+	var invalid *cronparser.ValidationError
+	if !errors.As(e, &invalid) || invalid.Field != "timezone" || invalid.Token != "Mars/Olympus" || invalid.Offset != 8 {
+		t.Errorf("unknown time zone error is %v", e)
+	}
+
+	_, e = cronparser.Compile("CRON_TZ=Asia/Jakarta 0 24 * * *")
+	if !errors.As(e, &invalid) || invalid.Field != "hour" || invalid.Offset != 23 ||
+		invalid.Suggestion != "CRON_TZ=Asia/Jakarta 0 23 * * *" {
+		t.Errorf("hour error is %v", e)
+	}
+
+	description, e := cronparser.Describe([]string{"CRON_TZ=America/New_York", "30", "9", "*", "*", "1-5"})
+	if want := "At 09:30, Monday through Friday, in the America/New_York time zone"; e != nil || description != want {
+		t.Errorf("description is %q, it should be %q", description, want)
+	}
+}
+
+func TestExpressionNextAllocations(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	x, _ := cronparser.Compile("*/15 9-17 * * MON-FRI")
+	from := time.Date(2021, 3, 26, 18, 0, 0, 0, berlin)
+
+	if allocs := testing.AllocsPerRun(100, func() { x.Next(from) }); allocs > 0 {
+		t.Errorf("Next allocates %v times, it should not allocate", allocs)
+	}
+}
+
+func BenchmarkParserNext(b *testing.B) {
+	from := time.Date(2021, 1, 29, 10, 0, 0, 0, time.UTC)
+	expr := []string{"*/15", "9-17", "*", "*", "MON-FRI"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parser := cronparser.New(&cronparser.Parser{
+			Timezone: "Asia/Jakarta",
+		})
+		parser.SetCurrentTime(from).Parse(expr)
+	}
+}
+
+func BenchmarkExpressionNext(b *testing.B) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	from := time.Date(2021, 1, 29, 10, 0, 0, 0, jakarta)
+	x, _ := cronparser.Compile("*/15 9-17 * * MON-FRI")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x.Next(from)
+	}
+}
+
+func TestWithZone(t *testing.T) {
+	tests := []struct {
+		expr, zone, want string
+	}{
+		{"0 8 * * *", "America/New_York", "CRON_TZ=America/New_York 0 8 * * *"},
+		{"CRON_TZ=Asia/Tokyo 0 8 * * *", "America/New_York", "CRON_TZ=Asia/Tokyo 0 8 * * *"},
+		{"TZ=Asia/Tokyo 0 8 * * *", "", "TZ=Asia/Tokyo 0 8 * * *"},
+		{"0 8 * * *", "", "0 8 * * *"},
+	}
+	for _, test := range tests {
+		if got := strings.Join(cronparser.WithZone(strings.Fields(test.expr), test.zone), " "); got != test.want {
+			t.Errorf("Cron format with zone not match: %s should be %s", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+	"github.com/KodepandaID/shigoto/pkg/dashboard"
+)
+
+var _ dashboard.Scheduler = &shigoto.Config{}
+
+type fakeDashboardScheduler struct {
+	fakeScheduler
+	complete func(shigoto.JobEvent)
+}
+
+func (f *fakeDashboardScheduler) OnComplete(h func(shigoto.JobEvent)) *shigoto.Config {
+	f.complete = h
+	return nil
+}
+
+func TestDashboardAssets(t *testing.T) {
+	d := dashboard.New(&fakeDashboardScheduler{})
+
+	for _, path := range []string{"/", "/app.js", "/style.css", "/api/jobs"} {
+		rec := httptest.NewRecorder()
+		d.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+		if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Errorf("GET %s status is %d", path, rec.Code)
+			t.Fail()
+		}
+	}
+}
+
+func TestDashboardEvents(t *testing.T) {
+	f := &fakeDashboardScheduler{}
+	srv := httptest.NewServer(dashboard.New(f))
+	defer srv.Close()
+
+	res, e := http.Get(srv.URL + "/events")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer res.Body.Close()
+
+	if res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Content type is %s", res.Header.Get("Content-Type"))
+		t.Fail()
+	}
+
+	r := bufio.NewReader(res.Body)
+	if line, _ := r.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("First line is %q", line)
+	}
+
+	f.complete(shigoto.JobEvent{JobName: "hello", Started: time.Now()})
+
+	for {
+		line, e := r.ReadString('\n')
+		if e != nil {
+			t.Fatal(e)
+		}
+		if line == "event: run\n" {
+			data, _ := r.ReadString('\n')
+			if !strings.Contains(data, `"job_name":"hello"`) {
+				t.Errorf("Data not match: %s", data)
+				t.Fail()
+			}
+			break
+		}
+	}
+}
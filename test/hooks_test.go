@@ -0,0 +1,111 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+)
+
+func TestHooks(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	events := make(chan string, 4)
+	client.OnStart(func(ev shigoto.JobEvent) {
+		events <- "start"
+	})
+	client.OnComplete(func(ev shigoto.JobEvent) {
+		events <- "complete"
+	})
+
+	client.Register("hello-panic", helloPanic)
+	_, e = client.Command("run-hello-hooks", "hello-panic").
+		Daily().
+		OnSuccess(func(ev shigoto.JobEvent) {
+			events <- "success"
+		}).
+		OnFailure(func(ev shigoto.JobEvent) {
+			if ev.Error == nil || !ev.Manual || ev.Attempt != 1 {
+				events <- "invalid"
+				return
+			}
+			events <- "failure"
+		}).
+		Do()
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-hooks")
+
+	if e := client.Trigger("run-hello-hooks"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	for _, want := range []string{"start", "failure", "complete"} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Errorf("Hook not match: hook is %s hook should be %s", got, want)
+				t.Fail()
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Hook %s is not called", want)
+		}
+	}
+}
+
+func TestHooksReplacedByDo(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	events := make(chan string, 2)
+	client.Register("hello", helloWithoutParams)
+	_, e = client.Command("run-hello-hooks-replaced", "hello").
+		Daily().
+		OnComplete(func(ev shigoto.JobEvent) {
+			events <- "complete"
+		}).
+		Do()
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-hooks-replaced")
+
+	// The hook is removed by the next Do without the hook
+	if _, e = client.Command("run-hello-hooks-replaced", "hello").Daily().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.Trigger("run-hello-hooks-replaced"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	select {
+	case <-events:
+		t.Fatal("The hook of the previous Do should be removed")
+	case <-time.After(time.Second):
+	}
+}
+
+func helloPanic() error {
+	panic(errors.New("Test with panic"))
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+)
+
+func TestListAndGet(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", hello)
+	if _, e = client.Command("run-hello-info", "hello", "usman").EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-info")
+
+	jobs, e := client.List()
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if len(jobs) == 0 {
+		t.Fatal("List should not be empty")
+		t.Fail()
+	}
+
+	job, e := client.Get("run-hello-info")
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if job.FuncName != "hello" || job.Cron != "* * * * *" || len(job.Params) != 1 || job.NextRun.IsZero() {
+		t.Errorf("Job info not match: %+v", job)
+		t.Fail()
+	}
+
+	if _, e := client.Get("run-hello-unknown"); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", helloWithoutParams)
+	if _, e = client.Command("run-hello-upcoming", "hello").EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-upcoming")
+
+	jobs, e := client.Upcoming(2 * time.Minute)
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	var found bool
+	for i, job := range jobs {
+		if i > 0 && job.NextRun.Before(jobs[i-1].NextRun) {
+			t.Error("Upcoming jobs should be ordered by the next run")
+			t.Fail()
+		}
+		if job.Name == "run-hello-upcoming" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Upcoming jobs should contain run-hello-upcoming")
+		t.Fail()
+	}
+}
@@ -0,0 +1,44 @@
+package test
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/KodepandaID/shigoto"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := shigoto.NewStdLogger(log.New(&buf, "", 0), shigoto.LevelWarn)
+
+	l.Info("job scheduled", "job", "hello")
+	l.Error("job failed", "job", "hello", "error", "Error")
+
+	if buf.String() != "ERROR job failed job=hello error=Error\n" {
+		t.Errorf("Log not match: %q", buf.String())
+		t.Fail()
+	}
+}
+
+func TestCommandEmptyName(t *testing.T) {
+	jobs := client.Command("", "schedule-test")
+	if _, e := jobs.Do(); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+}
+
+func TestClockFormatWrong(t *testing.T) {
+	for _, jobs := range []*shigoto.Jobs{
+		client.Command("cron-format", "schedule-test").At("1"),
+		client.Command("cron-format", "schedule-test").DailyAt("1"),
+		client.Command("cron-format", "schedule-test").WeeklyOn("1"),
+		client.Command("cron-format", "schedule-test").MonthlyOn("1"),
+	} {
+		if _, e := jobs.Do(); e == nil {
+			t.Error("Test should be fail")
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	h := shigoto.Recovery()(func(ctx context.Context, ev shigoto.JobEvent) error {
+		panic("Test with panic")
+	})
+
+	if e := h(context.Background(), shigoto.JobEvent{JobName: "recovery"}); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	h := shigoto.Timeout(10 * time.Millisecond)(func(ctx context.Context, ev shigoto.JobEvent) error {
+		<-ctx.Done()
+		return nil
+	})
+	if e := h(context.Background(), shigoto.JobEvent{JobName: "timeout"}); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+
+	h = shigoto.Timeout(time.Second)(func(ctx context.Context, ev shigoto.JobEvent) error {
+		return nil
+	})
+	if e := h(context.Background(), shigoto.JobEvent{JobName: "timeout"}); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	h := shigoto.Logging(shigoto.NewStdLogger(log.New(&buf, "", 0), shigoto.LevelInfo))(func(ctx context.Context, ev shigoto.JobEvent) error {
+		return errors.New("Error")
+	})
+	h(context.Background(), shigoto.JobEvent{JobName: "logging"})
+
+	if !strings.Contains(buf.String(), "INFO job started job=logging") || !strings.Contains(buf.String(), "ERROR job failed job=logging") {
+		t.Errorf("Log not match: %s", buf.String())
+		t.Fail()
+	}
+}
+
+func TestCallFuncContext(t *testing.T) {
+	shigoto.FuncStorage["hello-call-func-context"] = helloCallFuncContext
+	if e := shigoto.CallFuncWithParams("hello-call-func-context", []interface{}{"usman"}); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+}
+
+func helloCallFuncContext(ctx context.Context, name string) error {
+	if ctx == nil {
+		return errors.New("Context should not be nil")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+	prometheus "github.com/KodepandaID/shigoto/pkg/prometheus-exporter"
+)
+
+func TestPrometheusExporter(t *testing.T) {
+	c := &shigoto.Config{}
+	exporter := prometheus.New(c)
+	if c.Metrics != exporter {
+		t.Fatal("Exporter should be the metrics of the config")
+	}
+
+	exporter.ObserveRun("hello", 20*time.Millisecond, time.Second, nil)
+	exporter.ObserveRun("hello", 2*time.Second, time.Second, errors.New("Error"))
+	exporter.ObserveStorage("get_job", 3*time.Millisecond, nil)
+
+	rec := httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+
+	for _, line := range []string{
+		`shigoto_runs_total{job="hello"} 2`,
+		`shigoto_run_failures_total{job="hello"} 1`,
+		`shigoto_run_duration_seconds_bucket{job="hello",le="0.025"} 1`,
+		`shigoto_run_duration_seconds_bucket{job="hello",le="2.5"} 2`,
+		`shigoto_run_duration_seconds_bucket{job="hello",le="+Inf"} 2`,
+		`shigoto_run_duration_seconds_count{job="hello"} 2`,
+		`shigoto_schedule_lag_seconds_sum{job="hello"} 2`,
+		`shigoto_storage_operation_duration_seconds_count{op="get_job"} 1`,
+		`shigoto_storage_operation_errors_total{op="get_job"} 0`,
+		`shigoto_queue_depth 0`,
+		`# TYPE shigoto_registered_jobs gauge`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("Metrics should contain %s", line)
+			t.Fail()
+		}
+	}
+}
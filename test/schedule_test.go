@@ -1,12 +1,15 @@
 package test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/KodepandaID/shigoto"
+	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
 	"github.com/joho/godotenv"
 )
 
@@ -37,6 +40,28 @@ func TestCronFormat(t *testing.T) {
 	}
 }
 
+func TestCronFormatMacro(t *testing.T) {
+	client.Register("schedule-test", scheduleTest)
+	tests := map[string][]string{
+		"@daily":         {"@daily"},
+		"@every 1h30m":   {"@every", "1h30m"},
+		" 0  9 * * 1-5 ": {"0", "9", "*", "*", "1-5"},
+	}
+
+	for cron, val := range tests {
+		jobs := client.Command("cron-format", "schedule-test").CronFormat(cron)
+		if !reflect.DeepEqual(jobs.Cron, val) {
+			t.Errorf("Cron %q not match: %v", cron, jobs.Cron)
+			t.Fail()
+		}
+	}
+
+	if _, e := client.Command("cron-format", "schedule-test").CronFormat("@daily").At("09:00").Do(); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+}
+
 func TestAt(t *testing.T) {
 	client.Register("schedule-test", scheduleTest)
 	jobs := client.Command("cron-format", "schedule-test")
@@ -47,6 +72,53 @@ func TestAt(t *testing.T) {
 	}
 }
 
+func TestAtEmptyCronFormat(t *testing.T) {
+	for _, cron := range []string{"", "CRON_TZ=Asia/Jakarta"} {
+		_, e := (&shigoto.Config{}).Command("at-empty", "schedule-test").CronFormat(cron).At("10:00").Do()
+		var invalid *shigoto.BuildError
+		if !errors.As(e, &invalid) {
+			t.Errorf("At on the cron format %q should be a build error, got %v", cron, e)
+		}
+	}
+}
+
+func TestEverySecond(t *testing.T) {
+	client.Register("schedule-test", scheduleTest)
+	jobs := client.Command("cron-format", "schedule-test")
+	jobs.EverySecond()
+
+	if !reflect.DeepEqual(jobs.Cron, []string{"*", "*", "*", "*", "*", "*"}) {
+		t.Fail()
+	}
+}
+
+func TestEveryTenSeconds(t *testing.T) {
+	client.Register("schedule-test", scheduleTest)
+	jobs := client.Command("cron-format", "schedule-test")
+	jobs.EveryTenSeconds()
+
+	if !reflect.DeepEqual(jobs.Cron, []string{"*/10", "*", "*", "*", "*", "*"}) {
+		t.Fail()
+	}
+}
+
+func TestEverySeconds(t *testing.T) {
+	client.Register("schedule-test", scheduleTest)
+	jobs := client.Command("cron-format", "schedule-test")
+	jobs.EverySeconds(7)
+
+	if !reflect.DeepEqual(jobs.Cron, []string{"*/7", "*", "*", "*", "*", "*"}) {
+		t.Fail()
+	}
+
+	for _, n := range []int{0, 60} {
+		if _, e := client.Command("cron-format", "schedule-test").EverySeconds(n).Do(); e == nil {
+			t.Errorf("Interval %d should be fail", n)
+			t.Fail()
+		}
+	}
+}
+
 func TestEveryMinute(t *testing.T) {
 	client.Register("schedule-test", scheduleTest)
 	jobs := client.Command("cron-format", "schedule-test")
@@ -162,7 +234,7 @@ func TestWeekly(t *testing.T) {
 	jobs := client.Command("cron-format", "schedule-test")
 	jobs.Weekly()
 
-	if !reflect.DeepEqual(jobs.Cron, []string{"0", "0", "*/7", "*", "*"}) {
+	if !reflect.DeepEqual(jobs.Cron, []string{"0", "0", "*", "*", "0"}) {
 		t.Fail()
 	}
 }
@@ -172,7 +244,7 @@ func TestWeeklyOn(t *testing.T) {
 	jobs := client.Command("cron-format", "schedule-test")
 	jobs.WeeklyOn("1:00")
 
-	if !reflect.DeepEqual(jobs.Cron, []string{"0", "1", "*/7", "*", "*"}) {
+	if !reflect.DeepEqual(jobs.Cron, []string{"0", "1", "*", "*", "0"}) {
 		t.Fail()
 	}
 }
@@ -202,11 +274,47 @@ func TestQuarterly(t *testing.T) {
 	jobs := client.Command("cron-format", "schedule-test")
 	jobs.Quarterly()
 
-	if !reflect.DeepEqual(jobs.Cron, []string{"0", "0", "1", "*/6", "*"}) {
+	if !reflect.DeepEqual(jobs.Cron, []string{"0", "0", "1", "*/3", "*"}) {
 		t.Fail()
 	}
 }
 
+// The runs of the builders across the month and the year boundaries
+func TestBuilderDates(t *testing.T) {
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2021, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		jobs  *shigoto.Jobs
+		from  time.Time
+		hour  int
+		dates []time.Time
+	}{
+		{"Weekly", client.Command("cron-format", "schedule-test").Weekly(), day(1, 20), 0,
+			[]time.Time{day(1, 24), day(1, 31), day(2, 7), day(2, 14), day(2, 21), day(2, 28), day(3, 7)}},
+		{"WeeklyOn", client.Command("cron-format", "schedule-test").WeeklyOn("1:00"), day(1, 20), 1,
+			[]time.Time{day(1, 24), day(1, 31), day(2, 7), day(2, 14)}},
+		{"Quarterly", client.Command("cron-format", "schedule-test").Quarterly(), day(1, 1), 0,
+			[]time.Time{day(4, 1), day(7, 1), day(10, 1), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	for _, test := range tests {
+		expr, e := cronparser.CompileFields(test.jobs.Cron)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		next := test.from
+		for _, date := range test.dates {
+			next = expr.Next(next)
+			if want := date.Add(time.Duration(test.hour) * time.Hour); !next.Equal(want) {
+				t.Errorf("%s runs at %s, it should be %s", test.name, next, want)
+			}
+		}
+	}
+}
+
 func TestYearly(t *testing.T) {
 	client.Register("schedule-test", scheduleTest)
 	jobs := client.Command("cron-format", "schedule-test")
@@ -217,6 +325,41 @@ func TestYearly(t *testing.T) {
 	}
 }
 
+func TestBuildError(t *testing.T) {
+	client.Register("schedule-test", scheduleTest)
+	_, e := client.Command("", "schedule-unknown").DailyAt("9h").Do()
+
+	be, ok := e.(*shigoto.BuildError)
+	if !ok {
+		t.Fatalf("Error should be a BuildError: %v", e)
+	}
+	// empty name, clock format and unknown function
+	if len(be.Errors) != 3 {
+		t.Errorf("Errors not match: %v", be)
+		t.Fail()
+	}
+}
+
+func TestBuildErrorClockRange(t *testing.T) {
+	client.Register("schedule-test", scheduleTest)
+	for _, clock := range []string{"24:00", "23:60", "-1:00", "aa:00", "1:00:00"} {
+		if _, e := client.Command("cron-format", "schedule-test").DailyAt(clock).Do(); e == nil {
+			t.Errorf("Clock %s should be fail", clock)
+			t.Fail()
+		}
+	}
+}
+
+func TestBuildErrorCron(t *testing.T) {
+	client.Register("schedule-test", scheduleTest)
+	for _, cron := range []string{"60 * * * *", "* * *", "* * * * * * *"} {
+		if _, e := client.Command("cron-format", "schedule-test").CronFormat(cron).Do(); e == nil {
+			t.Errorf("Cron %s should be fail", cron)
+			t.Fail()
+		}
+	}
+}
+
 func scheduleTest() error {
 	return nil
 }
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/KodepandaID/shigoto"
+	"github.com/KodepandaID/shigoto/pkg/mongodb-connector"
 	"github.com/joho/godotenv"
 )
 
@@ -41,6 +43,16 @@ func TestErrorCreateInstance(t *testing.T) {
 	}
 }
 
+func TestErrorCreateInstanceTimezone(t *testing.T) {
+	if _, e := shigoto.New(&shigoto.Config{
+		DB:       os.Getenv("MONGO_URI"),
+		DBName:   "jobs-scheduler",
+		Timezone: "Asia/Atlantis",
+	}); e == nil {
+		t.Error("An unknown time zone should be an error")
+	}
+}
+
 func TestDoSchedule(t *testing.T) {
 	client, e := shigoto.New(&shigoto.Config{
 		DB:     os.Getenv("MONGO_URI"),
@@ -139,6 +151,384 @@ func TestMultipleDo(t *testing.T) {
 	client.Delete("run-hello-err")
 }
 
+func TestDoReschedule(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", helloWithoutParams)
+	if _, e = client.Command("run-hello-reschedule", "hello").EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if _, e = client.Command("run-hello-reschedule", "hello").Hourly().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Delete("run-hello-reschedule")
+}
+
+func TestDoReplacesParams(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", hello)
+	if _, e = client.Command("run-hello-replaced-params", "hello", "usman").EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if _, e = client.Command("run-hello-replaced-params", "hello", "yudha").EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	info, e := client.Get("run-hello-replaced-params")
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if len(info.Params) != 1 || len(info.Params[0]) != 1 || info.Params[0][0] != "yudha" {
+		t.Fatalf("The params %v should be the params of the last Do", info.Params)
+	}
+	if e := client.RemoveTask("run-hello-replaced-params", "usman"); e != shigoto.ErrTaskNotFound {
+		t.Errorf("The params of the previous Do should be removed, got %v", e)
+	}
+
+	client.Delete("run-hello-replaced-params")
+}
+
+func TestReschedule(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", helloWithoutParams)
+	if _, e = client.Command("run-hello-reschedule", "hello").EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.Reschedule("run-hello-reschedule", "0 * * * *"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.Reschedule("run-hello-reschedule", "60 * * * *"); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+	if e := client.Reschedule("run-hello-unknown", "0 * * * *"); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+
+	client.Delete("run-hello-reschedule")
+}
+
+func TestTrigger(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", hello)
+	_, e = client.Command("run-hello-trigger", "hello", "usman").Daily().Do()
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	if e := client.Trigger("run-hello-trigger"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.Trigger("run-hello-trigger", "yudha"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.Trigger("run-hello-unknown"); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+
+	client.Delete("run-hello-trigger")
+}
+
+func TestPauseAndTasks(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", hello)
+	if _, e = client.Command("run-hello-pause", "hello", "usman").EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-pause")
+
+	if e := client.Pause("run-hello-pause"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if job, _ := client.Get("run-hello-pause"); !job.Paused {
+		t.Error("Job should be paused")
+		t.Fail()
+	}
+	if e := client.Resume("run-hello-pause"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.Pause("run-hello-unknown"); e != shigoto.ErrJobNotFound {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+
+	if e := client.AddTask("run-hello-pause", "yudha"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.AddTask("run-hello-pause", "yudha"); e == nil {
+		t.Error("Test should be fail")
+		t.Fail()
+	}
+	if e := client.RemoveTask("run-hello-pause", "yudha"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if job, _ := client.Get("run-hello-pause"); len(job.Params) != 1 {
+		t.Errorf("Job params not match: %v", job.Params)
+		t.Fail()
+	}
+}
+
+func TestTasksIntegerParams(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	client.Register("count", func(n int) error { return nil })
+	if _, e = client.Command("run-count-int", "count", 5).EveryMinute().Do(); e != nil {
+		t.Fatal(e)
+	}
+	defer client.Delete("run-count-int")
+
+	// The params of the admin API are the float64 of JSON
+	if e := client.AddTask("run-count-int", float64(5)); e == nil {
+		t.Error("The params 5 should be registered already")
+	}
+	if e := client.RemoveTask("run-count-int", float64(5)); e != nil {
+		t.Fatal(e)
+	}
+	if e := client.Trigger("run-count-int"); e != shigoto.ErrJobNotFound {
+		t.Errorf("The task should be removed from the schedule, got %v", e)
+	}
+}
+
+func TestSyncStorage(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:           os.Getenv("MONGO_URI"),
+		DBName:       "jobs-scheduler",
+		Timeout:      time.Second * 3,
+		SyncInterval: time.Second,
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	var called int32
+	client.Register("hello-sync", func(name string) error {
+		atomic.AddInt32(&called, 1)
+		return nil
+	})
+	if _, e = client.Command("run-hello-sync", "hello-sync", "usman").Hourly().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-sync")
+
+	// The CLI requests a run with the connector
+	conn, e := mongodb.New(&mongodb.Connector{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := conn.InsertTrigger("run-hello-sync"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Run()
+	if atomic.LoadInt32(&called) != 1 {
+		t.Errorf("Job called %d times", called)
+		t.Fail()
+	}
+}
+
+func TestTriggerDoesNotDelayOtherJobs(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:      os.Getenv("MONGO_URI"),
+		DBName:  "jobs-scheduler",
+		Timeout: time.Second * 3,
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	var called int32
+	client.Register("hello-slow", func() error {
+		time.Sleep(time.Second * 4)
+		return nil
+	})
+	client.Register("hello-fast", func() error {
+		atomic.AddInt32(&called, 1)
+		return nil
+	})
+	if _, e = client.Command("run-hello-slow", "hello-slow").EverySecond().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-slow")
+	if _, e = client.Command("run-hello-fast", "hello-fast").EverySecond().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-fast")
+
+	// The scheduled runs of the slow job are due while it's running
+	if e := client.Trigger("run-hello-slow"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Run()
+	if atomic.LoadInt32(&called) < 2 {
+		t.Errorf("The other job should run on time while a triggered run is running, called %d times", called)
+	}
+}
+
+func TestTriggerScheduledRunOverlap(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:      os.Getenv("MONGO_URI"),
+		DBName:  "jobs-scheduler",
+		Timeout: time.Second * 3,
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	var running, overlaps, called int32
+	client.Register("hello-overlap", func() error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		time.Sleep(time.Millisecond * 1500)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&called, 1)
+		return nil
+	})
+	if _, e = client.Command("run-hello-overlap", "hello-overlap").EverySecond().Do(); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer client.Delete("run-hello-overlap")
+
+	if e := client.Trigger("run-hello-overlap"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Run()
+	if atomic.LoadInt32(&called) == 0 {
+		t.Error("The job should be running")
+	}
+	if atomic.LoadInt32(&overlaps) > 0 {
+		t.Error("The triggered run should not overlap the scheduled runs")
+	}
+}
+
+func TestLoadInvalidCron(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:      os.Getenv("MONGO_URI"),
+		DBName:  "jobs-scheduler",
+		Timeout: time.Second * 2,
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	// A cron format accepted by a previous version, stored with a next run
+	conn, e := mongodb.New(&mongodb.Connector{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	id, e := conn.InsertJobCollection(&mongodb.JobCollection{
+		JobName:    "run-hello-invalid-cron",
+		FuncName:   "hello",
+		CronFormat: []string{"*/100", "*", "*", "*", "MONDAY"},
+		NextDate:   time.Now().Add(time.Second),
+	})
+	if e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	defer conn.DeleteJobCollection("run-hello-invalid-cron")
+	if e := conn.InsertTask(id, "usman"); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+
+	client.Register("hello", hello)
+	if e := shigoto.LoadJobsFromPersistentStorage(client); e != nil {
+		t.Fatal(e)
+		t.Fail()
+	}
+	if e := client.Trigger("run-hello-invalid-cron"); e != shigoto.ErrJobNotFound {
+		t.Errorf("The job with an invalid cron format should not be scheduled, got %v", e)
+	}
+
+	// The scheduler keeps running after the next run of the job
+	client.Run()
+}
+
 func TestRun(t *testing.T) {
 	client, e := shigoto.New(&shigoto.Config{
 		DB:      os.Getenv("MONGO_URI"),
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/KodepandaID/shigoto"
+)
+
+func TestAddBlackout(t *testing.T) {
+	c := &shigoto.Config{}
+	if e := c.AddBlackout(shigoto.Blackout{
+		Name:     "database-maintenance",
+		Weekdays: []time.Weekday{time.Sunday},
+		Start:    "01:00",
+		End:      "03:00",
+		Policy:   shigoto.DeferRuns,
+	}); e != nil {
+		t.Fatal(e)
+	}
+
+	invalid := []shigoto.Blackout{
+		{Name: "clock", Start: "1am", End: "03:00"},
+		{Name: "range", Start: "01:00", End: "24:00"},
+		{Name: "empty", Start: "01:00", End: "01:00"},
+		{Name: "policy", Start: "01:00", End: "03:00", Policy: shigoto.BlackoutPolicy(9)},
+	}
+	for _, b := range invalid {
+		if e := c.AddBlackout(b); e == nil {
+			t.Errorf("Blackout %s should be an error", b.Name)
+		}
+	}
+}
+
+func TestNextRunsBetween(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:     os.Getenv("MONGO_URI"),
+		DBName: "jobs-scheduler",
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	client.Register("hello", helloWithoutParams)
+	if _, e = client.Command("run-hello-between", "hello").EveryThirtyMinutes().Between("08:00", "17:00").UnlessBetween("12:00", "12:59").Do(); e != nil {
+		t.Fatal(e)
+	}
+	defer client.Delete("run-hello-between")
+
+	runs, e := client.NextRuns("run-hello-between", 50)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for _, run := range runs {
+		if run.Hour() < 8 || run.Hour() > 17 || run.Hour() == 17 && run.Minute() > 0 || run.Hour() == 12 {
+			t.Errorf("%s is out of the time window", run)
+		}
+	}
+
+	if _, e = client.Command("run-hello-between-error", "hello").EveryMinute().Between("8am", "17:00").Do(); e == nil {
+		t.Error("An invalid time window should be an error")
+	}
+}
+
+func TestBlackoutSkipRuns(t *testing.T) {
+	client, e := shigoto.New(&shigoto.Config{
+		DB:       os.Getenv("MONGO_URI"),
+		DBName:   "jobs-scheduler",
+		Timezone: "UTC",
+		Timeout:  time.Second * 3,
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	now := time.Now().UTC()
+	if e := client.AddBlackout(shigoto.Blackout{
+		Name:   "maintenance-skip",
+		Start:  now.Format("15:04"),
+		End:    now.Add(time.Minute * 2).Format("15:04"),
+		Policy: shigoto.SkipRuns,
+	}); e != nil {
+		t.Fatal(e)
+	}
+
+	client.Register("hello", helloWithoutParams)
+	if _, e = client.Command("run-hello-blackout-skip", "hello").EverySecond().Do(); e != nil {
+		t.Fatal(e)
+	}
+	defer client.Delete("run-hello-blackout-skip")
+
+	client.Run()
+	// The last skipped run is stored in the background
+	time.Sleep(time.Millisecond * 500)
+
+	runs, e := client.History("run-hello-blackout-skip", 10)
+	if e != nil {
+		t.Fatal(e)
+	}
+	var skipped []shigoto.RunInfo
+	for _, run := range runs {
+		if run.Skipped != "" {
+			skipped = append(skipped, run)
+		}
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("The runs skipped by the blackout should be a single run: %+v", runs)
+	}
+
+	run := skipped[0]
+	if run.Skipped != "maintenance-skip" || run.SkippedRuns < 1 || run.LastScheduledAt == nil || run.LastScheduledAt.Before(run.ScheduledAt) {
+		t.Errorf("The skipped run is wrong: %+v", run)
+	}
+	if run.SkippedRuns > 1 && !run.LastScheduledAt.After(run.ScheduledAt) {
+		t.Errorf("The skipped run should have the scheduled times of the first and the last run: %+v", run)
+	}
+}
+
+func TestBlackoutDeferRuns(t *testing.T) {
+	// The blackout ends at the next minute
+	now := time.Now().UTC()
+	end := now.Truncate(time.Minute).Add(time.Minute)
+	client, e := shigoto.New(&shigoto.Config{
+		DB:       os.Getenv("MONGO_URI"),
+		DBName:   "jobs-scheduler",
+		Timezone: "UTC",
+		Timeout:  time.Until(end) + time.Second*3,
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if e := client.AddBlackout(shigoto.Blackout{
+		Name:   "maintenance-defer",
+		Start:  now.Format("15:04"),
+		End:    end.Format("15:04"),
+		Policy: shigoto.DeferRuns,
+	}); e != nil {
+		t.Fatal(e)
+	}
+
+	events := make(chan shigoto.JobEvent, 16)
+	client.Register("hello", helloWithoutParams)
+	_, e = client.Command("run-hello-blackout-defer", "hello").
+		EverySecond().
+		OnComplete(func(ev shigoto.JobEvent) {
+			select {
+			case events <- ev:
+			default:
+			}
+		}).
+		Do()
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer client.Delete("run-hello-blackout-defer")
+
+	client.Run()
+
+	var deferred []shigoto.JobEvent
+	for len(events) > 0 {
+		if ev := <-events; ev.Scheduled.Before(end) {
+			deferred = append(deferred, ev)
+		}
+	}
+	if len(deferred) != 1 {
+		t.Fatalf("The runs in the blackout should be deferred to a single run: %+v", deferred)
+	}
+	if deferred[0].Started.Before(end) {
+		t.Errorf("The deferred run started at %s before the end of the blackout %s", deferred[0].Started, end)
+	}
+	if !deferred[0].Scheduled.After(now.Add(-time.Second)) {
+		t.Errorf("The deferred run should keep its scheduled time in the blackout: %s", deferred[0].Scheduled)
+	}
+}
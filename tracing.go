@@ -0,0 +1,44 @@
+package shigoto
+
+import (
+	"context"
+)
+
+// Tracer starts a span for every run of a job and every storage
+// operation. The context of the run span is passed to the job
+// function, pkg/otel-tracer adapts OpenTelemetry to it.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span)
+}
+
+// Span is started by the Tracer
+type Span interface {
+	SetError(e error)
+	End()
+}
+
+// startSpan starts a span with the Tracer of the config, the returned
+// function ends the span with the error. It does nothing without a Tracer.
+func (c *Config) startSpan(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, func(e error)) {
+	t := c.Tracer
+	if t == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, span := t.Start(ctx, name, attrs)
+	return ctx, func(e error) {
+		if e != nil {
+			span.SetError(e)
+		}
+		span.End()
+	}
+}
+
+// traceStorage starts the spans of the storage operations
+// as the children of the run span.
+func (c *Config) traceStorage(ctx context.Context, op string) (context.Context, func(e error)) {
+	return c.startSpan(ctx, "shigoto.storage."+op, map[string]interface{}{
+		"db.system":    "mongodb",
+		"db.operation": op,
+	})
+}
@@ -1,162 +1,610 @@
 package shigoto
 
 import (
+	"context"
+	"fmt"
 	"math"
+	"reflect"
 	"time"
 
 	cronparser "github.com/KodepandaID/shigoto/pkg/cron-parser"
 	"github.com/KodepandaID/shigoto/pkg/mongodb-connector"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // After creating a new instance, the system will be load task
 // data from persistent storage and added to scheduled storage mapping.
-func LoadJobsFromPersistentStorage(c *Config) {
+func LoadJobsFromPersistentStorage(c *Config) error {
 	jobs, e := c.client.GetJobCollection()
 	if e != nil {
-		panic(e)
+		c.logger().Error("storage error", "op", "get_jobs", "error", e)
+		return e
 	}
 
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
 	for _, job := range jobs {
-		loc, _ := time.LoadLocation(c.Timezone)
-		tnow := time.Now().Local().In(loc)
-		nextDate := tnow
-
-		if tnow.Unix() > job.NextDate.Unix() {
-			schedule, eFatal := c.parser.SetCurrentTime(tnow).Parse(job.CronFormat)
-			if eFatal != nil {
-				panic(eFatal)
+		tnow := time.Now().In(c.loc)
+		nextDate := job.NextDate.In(c.loc)
+
+		cron := cronparser.WithZone(job.CronFormat, job.Timezone)
+
+		if cronparser.IsReboot(cron) {
+			nextDate = tnow
+		} else if tnow.Unix() > job.NextDate.Unix() {
+			next, e := nextRun(c, job.JobName, cron, job.ID.Timestamp(), tnow)
+			if e != nil {
+				c.logger().Error("job cannot be scheduled", "job", job.JobName, "error", e)
+				continue
 			}
-			nextDate = schedule.Next
+			nextDate = next
 		}
 
 		tasks, e := c.client.GetTasks(job.ID)
 		if e != nil {
-			panic(e)
+			c.logger().Error("storage error", "op", "get_tasks", "job", job.JobName, "error", e)
+			return e
 		}
 
 		for _, task := range tasks {
-			if ScheduleStorage[nextDate.String()] == nil {
-				ScheduleStorage[nextDate.String()] = []map[string]interface{}{
-					{
-						"id":        task.JobId.Hex(),
-						"job_name":  job.JobName,
-						"func_name": job.FuncName,
-						"params":    task.Params,
-						"cron":      job.CronFormat,
-					},
-				}
-			} else {
-				ss := ScheduleStorage[nextDate.String()].([]map[string]interface{})
-				_, match := checkSameJobName(job.JobName, ss)
-				if !match {
-					ScheduleStorage[nextDate.String()] = append(ss, map[string]interface{}{
-						"id":        task.JobId.Hex(),
-						"job_name":  job.JobName,
-						"func_name": job.FuncName,
-						"params":    task.Params,
-						"cron":      job.CronFormat,
-					})
-				}
+			t, e := newTask(task.JobId.Hex(), job.JobName, job.FuncName, task.Params, cron, nextDate)
+			if e != nil {
+				c.logger().Error("job cannot be scheduled", "job", job.JobName, "error", e)
+				break
 			}
+			t["paused"] = job.Paused
+			scheduleTask(t)
 		}
+		c.logger().Debug("job loaded", "job", job.JobName, "tasks", len(tasks), "next", nextDate)
 	}
+
+	return nil
 }
 
-func checkSameJobName(name string, j []map[string]interface{}) ([]map[string]interface{}, bool) {
-	var match bool
-	for index, task := range j {
-		if name == task["job_name"].(string) {
-			if (index + 1) <= len(j) {
-				j = append(j[:index], j[index+1:]...)
+// removeTasks removes every task of a job from the schedule storage
+// and returns them. The caller must hold storageLock.
+func removeTasks(name string) []map[string]interface{} {
+	var removed []map[string]interface{}
+	for key, jobs := range ScheduleStorage {
+		var keep []map[string]interface{}
+		for _, task := range jobs.([]map[string]interface{}) {
+			if task["job_name"].(string) == name {
+				removed = append(removed, task)
 			} else {
-				j = nil
+				keep = append(keep, task)
 			}
-			match = true
 		}
+
+		if len(keep) > 0 {
+			ScheduleStorage[key] = keep
+		} else {
+			delete(ScheduleStorage, key)
+		}
+	}
+
+	return removed
+}
+
+// newTask creates a task of the schedule storage, the cron format
+// is compiled once for the next runs of the task, see updateNextRun.
+// A cron format which cannot be compiled is an error.
+func newTask(id, jobName, funcName string, params []interface{}, cron []string, next time.Time) (map[string]interface{}, error) {
+	expr, e := cronparser.CompileFields(cron)
+	if e != nil {
+		return nil, e
+	}
+
+	return map[string]interface{}{
+		"id":        id,
+		"job_name":  jobName,
+		"func_name": funcName,
+		"params":    params,
+		"cron":      cron,
+		"expr":      expr,
+		"next":      next,
+		"paused":    false,
+	}, nil
+}
+
+func copyTask(task map[string]interface{}) map[string]interface{} {
+	t := make(map[string]interface{}, len(task))
+	for k, v := range task {
+		t[k] = v
+	}
+
+	return t
+}
+
+// scheduledOf returns the time the run of a task is scheduled at,
+// a run deferred by a blackout keeps the time it was due
+func scheduledOf(task map[string]interface{}) time.Time {
+	if scheduled, ok := task["scheduled"].(time.Time); ok {
+		return scheduled
 	}
 
-	return j, match
+	return task["next"].(time.Time)
+}
+
+// sameParams compares the params by their values, the numbers
+// are equal whatever their type like an int and a float64 of JSON
+func sameParams(a, b []interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(normalParam(a), normalParam(b))
+}
+
+// normalParam returns the param with every number as a float64,
+// every slice as a []interface{} and every map as a map[string]interface{}
+func normalParam(param interface{}) interface{} {
+	v := reflect.ValueOf(param)
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch k := v.Kind(); {
+	case k >= reflect.Int && k <= reflect.Int64:
+		return float64(v.Int())
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		return float64(v.Uint())
+	case k == reflect.Float32 || k == reflect.Float64:
+		return v.Float()
+	case (k == reflect.Slice || k == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = normalParam(v.Index(i).Interface())
+		}
+		return s
+	case k == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		m := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			m[key.String()] = normalParam(v.MapIndex(key).Interface())
+		}
+		return m
+	}
+
+	return param
+}
+
+// isNumber reports if the kind is an integer or a float
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// scheduleTask adds the task to the schedule storage at its next run.
+// It returns false when the job already has a task with the same params.
+// The caller must hold storageLock.
+func scheduleTask(task map[string]interface{}) bool {
+	for _, jobs := range ScheduleStorage {
+		for _, t := range jobs.([]map[string]interface{}) {
+			// check if the task having the same params,
+			// if they have the same params, it will be ignored.
+			if t["job_name"].(string) == task["job_name"].(string) &&
+				sameParams(t["params"].([]interface{}), task["params"].([]interface{})) {
+				return false
+			}
+		}
+	}
+
+	key := task["next"].(time.Time).String()
+	if ScheduleStorage[key] == nil {
+		ScheduleStorage[key] = []map[string]interface{}{task}
+	} else {
+		ScheduleStorage[key] = append(ScheduleStorage[key].([]map[string]interface{}), task)
+	}
+
+	return true
+}
+
+// findTasks returns a copy of every scheduled task of a job.
+func findTasks(name string) []map[string]interface{} {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	var tasks []map[string]interface{}
+	for _, jobs := range ScheduleStorage {
+		for _, task := range jobs.([]map[string]interface{}) {
+			if task["job_name"].(string) == name {
+				tasks = append(tasks, copyTask(task))
+			}
+		}
+	}
+
+	return tasks
+}
+
+// dueTasks removes every task scheduled at or before tnow from the
+// schedule storage, reschedules it and returns it to be running.
+// A paused task is rescheduled without running, a task without
+// a next run like @reboot after its run is never due. A task due
+// in a blackout is skipped or moved to the end of the blackout.
+func dueTasks(c *Config, tnow time.Time) []map[string]interface{} {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	var due []map[string]interface{}
+	for key, jobs := range ScheduleStorage {
+		ss := jobs.([]map[string]interface{})
+		if next := ss[0]["next"].(time.Time); next.IsZero() || next.After(tnow) {
+			continue
+		}
+		delete(ScheduleStorage, key)
+		due = append(due, ss...)
+	}
+
+	var tasks []map[string]interface{}
+	for _, task := range due {
+		scheduled := scheduledOf(task)
+		b, inBlackout := c.blackoutOf(task["next"].(time.Time))
+		if inBlackout && b.policy == DeferRuns {
+			t := copyTask(task)
+			t["next"] = b.window.endOf(task["next"].(time.Time).In(c.loc))
+			t["scheduled"] = scheduled
+			scheduleTask(t)
+			c.logger().Info("job deferred by blackout", "job", task["job_name"], "scheduled", scheduled, "blackout", b.name, "next", t["next"])
+			continue
+		}
+
+		updateNextRun(c, task, tnow)
+		if task["paused"].(bool) {
+			c.logger().Debug("job paused, run skipped", "job", task["job_name"], "scheduled", scheduled)
+			continue
+		}
+		// A task loaded before its calendars and time windows
+		// were set by Do can be due when it's excluded
+		if reason := c.excluded(task); reason != "" {
+			c.logger().Info("job excluded, run skipped", "job", task["job_name"], "scheduled", scheduled, "by", reason)
+			continue
+		}
+		if inBlackout {
+			c.logger().Info("job skipped by blackout", "job", task["job_name"], "scheduled", scheduled, "blackout", b.name)
+			go recordSkipped(c, task, scheduled, b)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	c.queued += len(tasks)
+
+	return tasks
 }
 
 // checkTask will check available task, if the task available,
 // the task will be running.
 func checkTask(c *Config) {
-	loc, _ := time.LoadLocation(c.Timezone)
+	synced := time.Now()
 	for {
-		tnow := time.Now().Local().In(loc)
-		if ScheduleStorage[tnow.String()] != nil {
-			ss := ScheduleStorage[tnow.String()].([]map[string]interface{})
-			if len(ss) > 0 {
-				var e error
-				for _, task := range ss {
-					funcName := task["func_name"].(string)
-					params := task["params"].([]interface{})
-					if len(params) == 0 {
-						e = CallFunc(funcName)
-					} else {
-						e = CallFuncWithParams(funcName, params)
-					}
-					updateJob(c, tnow, task["job_name"].(string), e)
-				}
-				updateNextRun(tnow.String(), c.Timezone, ss[0]["cron"].([]string), ss)
+		tnow := time.Now().In(c.loc).Truncate(time.Second)
+		if tnow.Sub(synced) >= c.SyncInterval {
+			synced = tnow
+			go syncStorage(c)
+		}
+		// The jobs run in their own goroutine, so a long run
+		// doesn't delay the other jobs
+		due := make(map[string][]map[string]interface{})
+		for _, task := range dueTasks(c, tnow) {
+			name := task["job_name"].(string)
+			due[name] = append(due[name], task)
+		}
+		for name, tasks := range due {
+			go runScheduled(c, name, tasks)
+		}
+		time.Sleep(time.Until(tnow.Add(time.Second)))
+	}
+}
+
+// runScheduled runs the due tasks of a job one after another. The runs
+// are skipped when the job is still running, like a triggered run.
+func runScheduled(c *Config, name string, tasks []map[string]interface{}) {
+	slot := c.runSlot(name)
+	select {
+	case slot <- struct{}{}:
+		defer func() { <-slot }()
+	default:
+		c.logger().Warn("job still running, run skipped", "job", name, "scheduled", scheduledOf(tasks[0]))
+		storageLock.Lock()
+		c.queued -= len(tasks)
+		storageLock.Unlock()
+		return
+	}
+
+	for _, task := range tasks {
+		scheduled := scheduledOf(task)
+		c.logger().Debug("job dispatched", "job", name, "scheduled", scheduled)
+		dispatch(c, task, scheduled, false)
+	}
+}
+
+// syncStorage loads the changes made to the persistent storage by
+// another process: the paused state of the jobs, the deleted jobs
+// and the requested runs.
+func syncStorage(c *Config) {
+	started := time.Now()
+	jobs, e := c.client.GetJobCollection()
+	if e != nil {
+		c.logger().Error("storage error", "op", "get_jobs", "error", e)
+		return
+	}
+
+	paused := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		paused[job.ID.Hex()] = job.Paused
+	}
+
+	storageLock.Lock()
+	for key, jobs := range ScheduleStorage {
+		var keep []map[string]interface{}
+		for _, task := range jobs.([]map[string]interface{}) {
+			p, ok := paused[task["id"].(string)]
+			if !ok && createdBefore(task["id"].(string), started) {
+				c.logger().Info("job deleted from storage", "job", task["job_name"])
+				continue
+			}
+			if ok {
+				task["paused"] = p
 			}
+			keep = append(keep, task)
 		}
+
+		if len(keep) > 0 {
+			ScheduleStorage[key] = keep
+		} else {
+			delete(ScheduleStorage, key)
+		}
+	}
+	storageLock.Unlock()
+
+	triggers, e := c.client.PopTriggers()
+	if e != nil {
+		c.logger().Error("storage error", "op", "pop_triggers", "error", e)
+	}
+	for _, t := range triggers {
+		if e := c.Trigger(t.JobName, t.Params...); e != nil {
+			c.logger().Warn("trigger skipped", "job", t.JobName, "error", e)
+		}
+	}
+}
+
+// anchorOf returns the registration time of a job from its id
+func anchorOf(id string) time.Time {
+	oid, e := primitive.ObjectIDFromHex(id)
+	if e != nil {
+		return time.Time{}
+	}
+
+	return oid.Timestamp()
+}
+
+// createdBefore reports if the job id was created before t. A job
+// created while the jobs were loaded is not deleted from the schedule,
+// the timestamp of an ObjectID has a precision of seconds.
+func createdBefore(id string, t time.Time) bool {
+	oid, e := primitive.ObjectIDFromHex(id)
+	if e != nil {
+		return false
 	}
+
+	return oid.Timestamp().Before(t.Truncate(time.Second))
 }
 
-// To create the next schedule after the success running.
-// The old schedule will be removed.
-func updateNextRun(key, timezone string, cron []string, tasks []map[string]interface{}) time.Time {
-	delete(ScheduleStorage, key)
+// dispatch is the single execution path for scheduled and manually
+// triggered runs of a task. The caller holds the run slot of the job.
+func dispatch(c *Config, task map[string]interface{}, scheduled time.Time, manual bool) {
+	defer func() {
+		storageLock.Lock()
+		c.queued--
+		storageLock.Unlock()
+	}()
 
-	parser := cronparser.New(&cronparser.Parser{
-		Timezone: timezone,
+	ev := JobEvent{
+		RunID:     primitive.NewObjectID().Hex(),
+		JobName:   task["job_name"].(string),
+		FuncName:  task["func_name"].(string),
+		Params:    task["params"].([]interface{}),
+		Scheduled: scheduled,
+		Started:   time.Now().In(c.loc),
+		Attempt:   1,
+		Manual:    manual,
+	}
+	o := c.runOptions(ev.JobName)
+	ctx, end := c.startSpan(context.Background(), "shigoto.run "+ev.JobName, map[string]interface{}{
+		"shigoto.job":       ev.JobName,
+		"shigoto.run_id":    ev.RunID,
+		"shigoto.attempt":   ev.Attempt,
+		"shigoto.scheduled": ev.Scheduled,
+		"shigoto.manual":    ev.Manual,
 	})
-	loc, _ := time.LoadLocation(timezone)
-	schedule, eFatal := parser.SetCurrentTime(time.Now().Local().In(loc)).Parse(cron)
-	if eFatal != nil {
-		panic(eFatal)
+
+	callHooks(o.hooks.start, ev)
+	ev.Error = safeRun(ctx, chain(invoke, o.middleware), ev)
+	ev.Duration = time.Since(ev.Started)
+
+	if ev.Error == nil {
+		c.logger().Debug("job done", "job", ev.JobName, "duration", ev.Duration)
+		callHooks(o.hooks.success, ev)
+	} else {
+		c.logger().Error("job failed", "job", ev.JobName, "duration", ev.Duration, "error", ev.Error)
+		callHooks(o.hooks.failure, ev)
 	}
+	callHooks(o.hooks.complete, ev)
 
-	var newTasks []map[string]interface{}
-	for _, task := range tasks {
-		newTasks = append(newTasks, map[string]interface{}{
-			"id":        task["id"].(string),
-			"job_name":  task["job_name"].(string),
-			"func_name": task["func_name"].(string),
-			"params":    task["params"].([]interface{}),
-			"cron":      task["cron"].([]string),
-		})
+	if m := c.Metrics; m != nil {
+		m.ObserveRun(ev.JobName, ev.Duration, ev.Started.Sub(ev.Scheduled), ev.Error)
+	}
+
+	updateJob(ctx, c, ev, func() {
+		end(ev.Error)
+	})
+}
+
+// runSlot returns the slot of the runs of a job, a run sends to the
+// slot before it starts and receives from it after it ends. The runs
+// of a job don't overlap.
+func (c *Config) runSlot(name string) chan struct{} {
+	c.runningLock.Lock()
+	defer c.runningLock.Unlock()
+
+	if c.running == nil {
+		c.running = make(map[string]chan struct{})
+	}
+	if c.running[name] == nil {
+		c.running[name] = make(chan struct{}, 1)
+	}
+
+	return c.running[name]
+}
+
+// To create the next schedule of a task after it is due.
+// The old schedule has been removed by the caller.
+func updateNextRun(c *Config, task map[string]interface{}, tnow time.Time) time.Time {
+	next, e := nextRunOf(c, task["job_name"].(string), task["expr"].(*cronparser.Expression), anchorOf(task["id"].(string)), tnow)
+	if e != nil {
+		c.logger().Error("job cannot be rescheduled", "job", task["job_name"], "error", e)
+		return time.Time{}
+	}
+
+	t := copyTask(task)
+	t["next"] = next
+	delete(t, "scheduled")
+	scheduleTask(t)
+	c.logger().Debug("job scheduled", "job", task["job_name"], "next", next)
+
+	return next
+}
+
+// nextRun compiles the cron format for a single next run,
+// see nextRunOf.
+func nextRun(c *Config, name string, cron []string, anchor, tnow time.Time) (time.Time, error) {
+	expr, e := cronparser.CompileFields(cron)
+	if e != nil {
+		return time.Time{}, e
+	}
+
+	return nextRunOf(c, name, expr, anchor, tnow)
+}
+
+// nextRunOf returns the next run of a compiled cron format of a job, it's
+// computed in the time zone of the job and returned in the time zone
+// of the scheduler. The dates excluded by the calendars of the job
+// are skipped. The intervals of @every start at the anchor,
+// the registration time of the job.
+// The next run of @reboot is zero, it's never due again.
+func nextRunOf(c *Config, name string, expr *cronparser.Expression, anchor, tnow time.Time) (time.Time, error) {
+	if expr == nil {
+		return time.Time{}, fmt.Errorf("Cron format of the job %q is not compiled", name)
+	}
+
+	next := expr.NextSince(anchor, tnow.In(c.loc))
+	if next.IsZero() && !expr.IsReboot() {
+		return next, cronparser.ErrNeverMatches
+	}
+
+	next, e := skipExcluded(expr, anchor, next, c.scheduleOptions(name))
+	if e != nil {
+		return next, e
+	}
+
+	return next.In(c.loc), nil
+}
+
+// excluded returns why the scheduled run of the task is excluded by the
+// calendars or the time windows of the job, it's empty when the task runs.
+// The run is matched in the time zone of the job.
+func (c *Config) excluded(task map[string]interface{}) string {
+	o := c.scheduleOptions(task["job_name"].(string))
+	if len(o.calendars) == 0 && len(o.windows) == 0 {
+		return ""
+	}
+
+	scheduled := scheduledOf(task).In(c.jobLocation(task["expr"].(*cronparser.Expression)))
+	if cal := excludedBy(o.calendars, scheduled); cal != nil {
+		return "calendar " + cal.Name()
 	}
+	if _, ok := deniedBy(o.windows, scheduled); ok {
+		return "time window"
+	}
+
+	return ""
+}
 
-	ScheduleStorage[schedule.Next.String()] = newTasks
+// jobLocation returns the time zone of a compiled cron format,
+// it's the time zone of the scheduler without `CRON_TZ=`
+func (c *Config) jobLocation(expr *cronparser.Expression) *time.Location {
+	if expr != nil && expr.Location() != nil {
+		return expr.Location()
+	}
 
-	return schedule.Next
+	return c.loc
+}
+
+// loadTimezone loads the location of a time zone like America/New_York,
+// an unknown time zone is an error
+func loadTimezone(name string) (*time.Location, error) {
+	loc, e := time.LoadLocation(name)
+	if e != nil || name == "" {
+		return nil, fmt.Errorf("Time zone %q is unknown", name)
+	}
+
+	return loc, nil
 }
 
 // updateJob to updating persistent data like total_run, total_error,
-// success_rate and error_rate after running the task.
-func updateJob(c *Config, tnow time.Time, jobName string, e error) {
+// success_rate and error_rate after running the task and to store
+// the run history. A manual run keeps the next_date as it is.
+// The storage operations are the children of the ctx of the run,
+// end is called when they are done.
+func updateJob(ctx context.Context, c *Config, ev JobEvent, end func()) {
 	go func() {
+		defer end()
+
 		eInc := 0
-		if e != nil {
+		var errMsg string
+		if ev.Error != nil {
 			eInc = 1
+			errMsg = ev.Error.Error()
 		}
 
-		job, e := c.client.GetOneJobCollection(jobName)
-		if e == nil {
-			successRate, errRate := countSuccessAndErrorRate(float64(job.TotalRun+1), float64(job.TotalError+eInc))
+		client := c.client.WithContext(ctx)
+		job, e := client.GetOneJobCollection(ev.JobName)
+		if e != nil {
+			c.logger().Error("storage error", "op", "get_job", "job", ev.JobName, "error", e)
+			return
+		}
 
-			schedule, eFatal := c.parser.SetCurrentTime(tnow).Parse(job.CronFormat)
-			if eFatal != nil {
-				panic(eFatal)
+		successRate, errRate := countSuccessAndErrorRate(float64(job.TotalRun+1), float64(job.TotalError+eInc))
+
+		var nextDate time.Time
+		if !ev.Manual {
+			next, e := nextRun(c, job.JobName, cronparser.WithZone(job.CronFormat, job.Timezone), job.ID.Timestamp(), ev.Scheduled)
+			if e != nil {
+				c.logger().Error("job cannot be rescheduled", "job", job.JobName, "error", e)
 			}
+			nextDate = next
+		}
+
+		if e := client.UpdateJobCollection(job.ID, &mongodb.JobCollection{
+			NextDate:    nextDate,
+			LastDate:    ev.Started,
+			LastManual:  ev.Manual,
+			SuccessRate: successRate,
+			ErrorRate:   errRate,
+		}, eInc); e != nil {
+			c.logger().Error("storage error", "op", "update_job", "job", job.JobName, "error", e)
+		}
 
-			c.client.UpdateJobCollection(job.ID, &mongodb.JobCollection{
-				NextDate:    schedule.Next,
-				SuccessRate: successRate,
-				ErrorRate:   errRate,
-			}, eInc)
+		if e := client.InsertRun(&mongodb.RunCollection{
+			RunID:       ev.RunID,
+			JobId:       job.ID,
+			JobName:     job.JobName,
+			Params:      ev.Params,
+			ScheduledAt: ev.Scheduled,
+			StartedAt:   ev.Started,
+			Duration:    ev.Duration,
+			Manual:      ev.Manual,
+			Error:       errMsg,
+		}); e != nil {
+			c.logger().Error("storage error", "op", "insert_run", "job", job.JobName, "error", e)
 		}
 	}()
 }
@@ -0,0 +1,243 @@
+package shigoto
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/KodepandaID/shigoto/pkg/mongodb-connector"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// window is a time of the day from start until end, end is excluded.
+// A window with an end before its start crosses midnight. A window
+// is only on its weekdays, the days it starts.
+type window struct {
+	start, end time.Duration // Since midnight
+	weekdays   [7]bool
+	unless     bool // The job doesn't run in the window
+}
+
+// BlackoutPolicy is what happens to the runs due in a blackout
+type BlackoutPolicy int
+
+const (
+	// SkipRuns skips the runs due in the blackout, they are stored
+	// in the history of the job as a single skipped run
+	SkipRuns BlackoutPolicy = iota
+	// DeferRuns runs the jobs due in the blackout once at its end,
+	// the run keeps the time it was scheduled at
+	DeferRuns
+)
+
+// Blackout is a maintenance window of the scheduler, like the database
+// maintenance every Sunday from 01:00 until 03:00. The times are in the
+// time zone of the scheduler and the end is excluded.
+type Blackout struct {
+	Name     string
+	Weekdays []time.Weekday // The days the blackout starts, every day when it's empty
+	Start    string         // hh:mm
+	End      string         // hh:mm, an end before the start is on the next day
+	Policy   BlackoutPolicy
+}
+
+// blackout is a parsed Blackout
+type blackout struct {
+	name   string
+	window window
+	policy BlackoutPolicy
+}
+
+// newWindow creates a window of the `hh:mm` times on every day
+func newWindow(start, end string) (window, []error) {
+	startHour, startMinute, errs := parseClock(start)
+	endHour, endMinute, endErrs := parseClock(end)
+	errs = append(errs, endErrs...)
+
+	w := window{
+		start: time.Duration(startHour)*time.Hour + time.Duration(startMinute)*time.Minute,
+		end:   time.Duration(endHour)*time.Hour + time.Duration(endMinute)*time.Minute,
+	}
+	for i := range w.weekdays {
+		w.weekdays[i] = true
+	}
+
+	return w, errs
+}
+
+// Between to run the job only from start until end, like "08:00" and
+// "17:00" in the time zone of the job. The runs at end are included,
+// an end before the start is on the next day.
+func (j *Jobs) Between(start, end string) *Jobs {
+	return j.window(start, end, false)
+}
+
+// UnlessBetween to skip the runs of the job from start until end,
+// the runs at end are skipped too
+func (j *Jobs) UnlessBetween(start, end string) *Jobs {
+	return j.window(start, end, true)
+}
+
+func (j *Jobs) window(start, end string, unless bool) *Jobs {
+	w, errs := newWindow(start, end)
+	for _, e := range errs {
+		j.addErr(e)
+	}
+	if len(errs) > 0 {
+		return j
+	}
+
+	// The runs in the minute of the end are in the window
+	w.end += time.Minute
+	w.unless = unless
+	j.windows = append(j.windows, w)
+
+	return j
+}
+
+// AddBlackout to add a maintenance window to every job, the runs due
+// in the blackout are skipped or deferred to its end by the policy
+func (c *Config) AddBlackout(b Blackout) error {
+	w, errs := newWindow(b.Start, b.End)
+	if len(errs) > 0 {
+		return fmt.Errorf("Blackout %q is invalid: %w", b.Name, errs[0])
+	}
+	if w.start == w.end {
+		return fmt.Errorf("Blackout %q is invalid: the start and the end are the same", b.Name)
+	}
+	if b.Policy != SkipRuns && b.Policy != DeferRuns {
+		return fmt.Errorf("Blackout %q is invalid: the policy %d is unknown", b.Name, b.Policy)
+	}
+	if len(b.Weekdays) > 0 {
+		w.weekdays = [7]bool{}
+		for _, day := range b.Weekdays {
+			w.weekdays[day%7] = true
+		}
+	}
+
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	c.blackouts = append(c.blackouts, blackout{name: b.Name, window: w, policy: b.Policy})
+
+	return nil
+}
+
+// blackoutOf returns the blackout of the scheduler which contains t
+func (c *Config) blackoutOf(t time.Time) (blackout, bool) {
+	c.optionsLock.Lock()
+	defer c.optionsLock.Unlock()
+
+	t = t.In(c.loc)
+	for _, b := range c.blackouts {
+		if b.window.contains(t) {
+			return b, true
+		}
+	}
+
+	return blackout{}, false
+}
+
+// sinceMidnight returns the wall clock of t as a duration since midnight
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// contains reports if the wall clock of t is in the window
+func (w window) contains(t time.Time) bool {
+	since, day := sinceMidnight(t), t.Weekday()
+	if w.start < w.end {
+		return w.weekdays[day] && since >= w.start && since < w.end
+	}
+
+	switch {
+	case since >= w.start:
+		return w.weekdays[day]
+	case since < w.end:
+		return w.weekdays[(day+6)%7]
+	}
+
+	return false
+}
+
+// allows reports if the window allows a run at t
+func (w window) allows(t time.Time) bool {
+	return w.contains(t) != w.unless
+}
+
+// startOf returns the start of the window which contains t
+func (w window) startOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	if w.start >= w.end && sinceMidnight(t) < w.end {
+		day--
+	}
+
+	return time.Date(year, month, day, 0, 0, int(w.start/time.Second), 0, t.Location())
+}
+
+// endOf returns the end of the window which contains t
+func (w window) endOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	if w.start >= w.end && sinceMidnight(t) >= w.start {
+		day++
+	}
+
+	return time.Date(year, month, day, 0, 0, int(w.end/time.Second), 0, t.Location())
+}
+
+// startAfter returns the first start of the window after t
+func (w window) startAfter(t time.Time) time.Time {
+	year, month, day := t.Date()
+	for i := 0; i <= 7; i++ {
+		start := time.Date(year, month, day+i, 0, 0, int(w.start/time.Second), 0, t.Location())
+		if start.After(t) && w.weekdays[start.Weekday()] {
+			return start
+		}
+	}
+
+	return time.Time{}
+}
+
+// resume returns the first time after t the window allows a run,
+// t is not allowed by the window
+func (w window) resume(t time.Time) time.Time {
+	if w.unless {
+		return w.endOf(t)
+	}
+
+	return w.startAfter(t)
+}
+
+// deniedBy returns the first window which doesn't allow a run at t
+func deniedBy(windows []window, t time.Time) (window, bool) {
+	for _, w := range windows {
+		if !w.allows(t) {
+			return w, true
+		}
+	}
+
+	return window{}, false
+}
+
+// recordSkipped stores a run skipped by a blackout in the history of the job.
+// The runs of a task skipped by the same blackout are a single run of the history.
+func recordSkipped(c *Config, task map[string]interface{}, scheduled time.Time, b blackout) {
+	id, _ := primitive.ObjectIDFromHex(task["id"].(string))
+	params := task["params"].([]interface{})
+
+	h := fnv.New64a()
+	fmt.Fprint(h, normalParam(params))
+	start := b.window.startOf(scheduled.In(c.loc))
+
+	if e := c.client.RecordSkipped(&mongodb.RunCollection{
+		RunID:       fmt.Sprintf("%s-%d-%x", id.Hex(), start.Unix(), h.Sum64()),
+		JobId:       id,
+		JobName:     task["job_name"].(string),
+		Params:      params,
+		ScheduledAt: scheduled,
+		StartedAt:   time.Now().In(c.loc),
+		Skipped:     b.name,
+	}); e != nil {
+		c.logger().Error("storage error", "op", "record_skipped", "job", task["job_name"], "error", e)
+	}
+}